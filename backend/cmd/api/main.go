@@ -7,6 +7,7 @@ import (
 
 	"livekit-consulting/backend/internal/config"
 	"livekit-consulting/backend/internal/database"
+	"livekit-consulting/backend/internal/events"
 	"livekit-consulting/backend/internal/handler"
 	"livekit-consulting/backend/internal/middleware"
 	"livekit-consulting/backend/internal/repository"
@@ -29,14 +30,35 @@ func main() {
 	}
 	defer db.Close()
 
+	var eventBus events.Bus
+	switch cfg.EventBusProvider {
+	case "postgres":
+		eventBus, err = events.NewPostgresBus(db, cfg.DatabaseURL)
+	case "memory":
+		eventBus = events.NewMemoryBus()
+	default:
+		log.Fatal().Msg("Unsupported event bus provider")
+	}
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to create event bus")
+	}
+	defer eventBus.Close()
+
 	userRepo := repository.NewUserRepository(db)
-	roomRepo := repository.NewRoomRepository(db)
-	participantRepo := repository.NewParticipantRepository(db)
+	roomRepo := repository.NewRoomRepository(db, eventBus)
+	participantRepo := repository.NewParticipantRepository(db, eventBus)
 	postRepo := repository.NewPostRepository(db)
 	resetTokenRepo := repository.NewPasswordResetTokenRepository(db)
 	inviteRepo := repository.NewInviteRepository(db)
-	messageRepo := repository.NewMessageRepository(db)
+	lobbyRequestRepo := repository.NewLobbyRequestRepository(db)
+	messageRepo := repository.NewMessageRepository(db, eventBus)
 	attachmentRepo := repository.NewAttachmentRepository(db)
+	reactionRepo := repository.NewReactionRepository(db, eventBus)
+	transcriptRepo := repository.NewTranscriptRepository(db)
+	agentRepo := repository.NewAgentRepository(db)
+	redactionPolicyRepo := repository.NewRedactionPolicyRepository(db)
+	auditLogRepo := repository.NewAuditLogRepository(db)
+	meetingSessionRepo := repository.NewMeetingSessionRepository(db)
 
 	var emailProvider email.EmailProvider
 	if cfg.EmailProvider == "sendgrid" {
@@ -64,6 +86,7 @@ func main() {
 		cfg.LiveKitAPIKey,
 		cfg.LiveKitAPISecret,
 		cfg.LiveKitURL,
+		cfg.LiveKitTokenTTL,
 	)
 
 	authService := service.NewAuthService(
@@ -107,12 +130,42 @@ func main() {
 		log.Fatal().Err(err).Msg("Failed to create file storage")
 	}
 
-	messageService := service.NewMessageService(messageRepo, participantRepo, attachmentRepo, roomRepo)
+	messageService := service.NewMessageService(messageRepo, participantRepo, attachmentRepo, roomRepo, reactionRepo, emailService, cfg.FrontendURL)
+	realtimeService := service.NewRealtimeService(eventBus, messageRepo, participantRepo, roomRepo)
 
-	s3TranscriptStorage, err := service.NewS3TranscriptStorage(cfg)
+	transcriptStorage, err := service.NewTranscriptStorage(cfg, fileStorage)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to create transcript storage")
+	}
+	auditService := service.NewAuditService(auditLogRepo, roomRepo)
+	redactionPatterns, err := service.ParseRedactionPatterns(cfg.TranscriptRedactionPatterns)
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to create S3 transcript storage")
+		log.Fatal().Err(err).Msg("Failed to parse transcript redaction patterns")
 	}
+	redactionService, err := service.NewRedactionService(redactionPolicyRepo, roomRepo, participantRepo, auditService, service.RedactionDefaults{
+		Enabled:    cfg.TranscriptRedactionEnabled,
+		Categories: cfg.TranscriptRedactionCategories,
+		Patterns:   redactionPatterns,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to create transcript redaction service")
+	}
+	transcriptService := service.NewTranscriptService(transcriptRepo, messageRepo, participantRepo, roomRepo, transcriptStorage, redactionService, auditService)
+
+	meetingSessionService := service.NewMeetingSessionService(meetingSessionRepo, participantRepo)
+	moderationService := service.NewModerationService(livekitService, roomRepo, participantRepo, auditService)
+	lobbyService := service.NewLobbyService(lobbyRequestRepo, inviteRepo, participantRepo, participantService, auditService, eventBus)
+
+	agentService := service.NewAgentService(agentRepo)
+	agentEventService := service.NewAgentEventService(messageService, transcriptService, agentService, redactionService, meetingSessionService, roomRepo, eventBus)
+	agentEventDispatcher := service.NewAgentEventDispatcher()
+	agentEventDispatcher.Register(service.AgentEventTranscriptUploaded, agentEventService.HandleTranscriptUploaded)
+	agentEventDispatcher.Register(service.AgentEventLiveCaption, agentEventService.HandleLiveCaption)
+	agentEventDispatcher.Register(service.AgentEventMeetingSummary, agentEventService.HandleMeetingSummary)
+	agentEventDispatcher.Register(service.AgentEventActionItems, agentEventService.HandleActionItems)
+	agentEventDispatcher.Register(service.AgentEventAgentJoined, agentEventService.HandleAgentPresence)
+	agentEventDispatcher.Register(service.AgentEventAgentLeft, agentEventService.HandleAgentPresence)
+	liveKitWebhookService := service.NewLiveKitWebhookService(roomRepo, participantRepo, meetingSessionRepo, messageService, eventBus)
 
 	authHandler := handler.NewAuthHandler(authService)
 	roomHandler := handler.NewRoomHandler(roomService)
@@ -120,8 +173,16 @@ func main() {
 	postHandler := handler.NewPostHandler(postService)
 	messageHandler := handler.NewMessageHandler(messageService)
 	attachmentHandler := handler.NewAttachmentHandler(fileStorage)
-	agentWebhookHandler := handler.NewAgentWebhookHandler(messageService)
-	transcriptHandler := handler.NewTranscriptHandler(messageRepo, s3TranscriptStorage)
+	agentWebhookHandler := handler.NewAgentWebhookHandler(agentEventDispatcher)
+	agentRegistryHandler := handler.NewAgentRegistryHandler(agentService)
+	liveKitWebhookHandler := handler.NewLiveKitWebhookHandler(liveKitWebhookService, cfg.LiveKitAPIKey, cfg.LiveKitAPISecret)
+	transcriptHandler := handler.NewTranscriptHandler(transcriptService, redactionService)
+	auditHandler := handler.NewAuditHandler(auditService)
+	meetingSessionHandler := handler.NewMeetingSessionHandler(meetingSessionService)
+	moderationHandler := handler.NewModerationHandler(moderationService)
+	lobbyHandler := handler.NewLobbyHandler(lobbyService)
+	webSocketHandler := handler.NewWebSocketHandler(realtimeService, cfg.CORSAllowedOrigins)
+	eventStreamHandler := handler.NewEventStreamHandler(realtimeService)
 
 	r := mux.NewRouter()
 
@@ -130,28 +191,58 @@ func main() {
 
 	api := r.PathPrefix("/api").Subrouter()
 
-	api.HandleFunc("/agent-webhook", agentWebhookHandler.HandleWebhook).Methods("POST")
+	agentWebhook := middleware.WebhookSignatureMiddleware(cfg.AgentWebhookSecret, cfg.AgentWebhookTolerance)
+	api.Handle("/agent-webhook", agentWebhook(http.HandlerFunc(agentWebhookHandler.HandleWebhook))).Methods("POST")
+	api.HandleFunc("/livekit-webhook", liveKitWebhookHandler.HandleWebhook).Methods("POST")
 
 	api.HandleFunc("/auth/signup", authHandler.SignUp).Methods("POST")
 	api.HandleFunc("/auth/signin", authHandler.SignIn).Methods("POST")
 	api.HandleFunc("/auth/reset-password", authHandler.RequestPasswordReset).Methods("POST")
 	api.HandleFunc("/auth/reset-password/confirm", authHandler.ResetPassword).Methods("POST")
-	api.HandleFunc("/rooms/{roomId}/join_external", participantHandler.JoinRoom).Methods("POST")
+	api.HandleFunc("/rooms/{roomId}/join_external", lobbyHandler.JoinRoom).Methods("POST")
+
+	// Streaming endpoints also accept the JWT as a query parameter because
+	// browsers cannot attach headers to WebSocket and EventSource requests.
+	streamAPI := api.PathPrefix("/app").Subrouter()
+	streamAPI.Use(middleware.StreamAuthMiddleware(cfg.JWTSecret, userRepo))
+
+	streamAPI.HandleFunc("/rooms/{roomId}/ws", webSocketHandler.ServeRoomSocket).Methods("GET")
+	streamAPI.HandleFunc("/rooms/{roomId}/events", eventStreamHandler.ServeRoomEvents).Methods("GET")
 
 	authAPI := api.PathPrefix("/app").Subrouter()
 	authAPI.Use(middleware.AuthMiddleware(cfg.JWTSecret, userRepo))
 
+	authAPI.HandleFunc("/transcript/search", transcriptHandler.SearchTranscripts).Methods("GET")
+	authAPI.HandleFunc("/rooms/{roomId}/transcript/search", transcriptHandler.SearchRoomTranscripts).Methods("GET")
+	authAPI.HandleFunc("/rooms/{roomId}/transcript/redaction-policy", transcriptHandler.GetRedactionPolicy).Methods("GET")
+	authAPI.HandleFunc("/rooms/{roomId}/transcript/redaction-policy", transcriptHandler.UpdateRedactionPolicy).Methods("PUT")
+	// Registered before the raw file route, whose key pattern would match them.
+	authAPI.HandleFunc("/rooms/{roomId}/transcript/{messageId}/segments", transcriptHandler.GetTranscriptSegments).Methods("GET")
+	authAPI.HandleFunc("/rooms/{roomId}/transcript/{messageId}/export", transcriptHandler.ExportTranscript).Methods("GET")
+	authAPI.HandleFunc("/rooms/{roomId}/transcript/{messageId}/segments/{segmentIndex}", transcriptHandler.CorrectTranscriptSegment).Methods("PUT")
+	authAPI.HandleFunc("/rooms/{roomId}/transcript/{messageId}/revisions", transcriptHandler.GetTranscriptRevisions).Methods("GET")
 	authAPI.HandleFunc("/rooms/{roomId}/transcript/{messageId}/{s3KeyPath:.+}", transcriptHandler.GetTranscript).Methods("GET")
 	authAPI.HandleFunc("/rooms", roomHandler.CreateRoom).Methods("POST")
 	authAPI.HandleFunc("/rooms", roomHandler.GetUserRooms).Methods("GET")
 	authAPI.HandleFunc("/rooms/{roomId}", roomHandler.GetRoomDetails).Methods("GET")
 	authAPI.HandleFunc("/rooms/{roomId}/livekit_create", roomHandler.CreateRoomAtLiveKit).Methods("POST")
+	authAPI.HandleFunc("/rooms/{roomId}/settings", roomHandler.UpdateRoomSettings).Methods("PUT")
 	authAPI.HandleFunc("/rooms/{roomId}", roomHandler.DeleteRoom).Methods("DELETE")
+	authAPI.HandleFunc("/rooms/{roomId}/audit-log", auditHandler.GetRoomAuditLog).Methods("GET")
+	authAPI.HandleFunc("/rooms/{roomId}/sessions", meetingSessionHandler.ListSessions).Methods("GET")
+	authAPI.HandleFunc("/rooms/{roomId}/sessions/{sessionId}", meetingSessionHandler.GetSession).Methods("GET")
+	authAPI.HandleFunc("/rooms/{roomId}/call/participants", moderationHandler.ListCallParticipants).Methods("GET")
+	authAPI.HandleFunc("/rooms/{roomId}/call/participants/{identity}", moderationHandler.RemoveParticipant).Methods("DELETE")
+	authAPI.HandleFunc("/rooms/{roomId}/call/participants/{identity}/permissions", moderationHandler.UpdatePermissions).Methods("PUT")
+	authAPI.HandleFunc("/rooms/{roomId}/call/participants/{identity}/tracks/{trackSid}/mute", moderationHandler.MuteTrack).Methods("PUT")
+	authAPI.HandleFunc("/rooms/{roomId}/lobby", lobbyHandler.ListPending).Methods("GET")
+	authAPI.HandleFunc("/rooms/{roomId}/lobby/{requestId}/approve", lobbyHandler.Approve).Methods("POST")
+	authAPI.HandleFunc("/rooms/{roomId}/lobby/{requestId}/deny", lobbyHandler.Deny).Methods("POST")
 
 	authAPI.HandleFunc("/rooms/{roomId}/participants", participantHandler.AddParticipant).Methods("POST")
 	authAPI.HandleFunc("/rooms/{roomId}/participants", participantHandler.GetParticipants).Methods("GET")
 	authAPI.HandleFunc("/rooms/{roomId}/participants/{participantId}", participantHandler.RemoveParticipant).Methods("DELETE")
-	// authAPI.HandleFunc("/rooms/{roomId}/join_external", participantHandler.JoinRoom).Methods("POST")
+	// authAPI.HandleFunc("/rooms/{roomId}/join_external", lobbyHandler.JoinRoom).Methods("POST")
 	authAPI.HandleFunc("/rooms/{roomId}/join_internal", participantHandler.JoinRoomInternal).Methods("POST")
 
 	authAPI.HandleFunc("/rooms/{roomId}/invite_participants_to_join_meeting", participantHandler.InviteParticipantsToJoinMeeting).Methods("POST")
@@ -163,6 +254,18 @@ func main() {
 
 	authAPI.HandleFunc("/rooms/{roomId}/messages", messageHandler.CreateMessage).Methods("POST")
 	authAPI.HandleFunc("/rooms/{roomId}/messages", messageHandler.GetMessages).Methods("GET")
+	authAPI.HandleFunc("/rooms/{roomId}/messages/search", messageHandler.SearchMessages).Methods("GET")
+	authAPI.HandleFunc("/rooms/{roomId}/messages/{messageId}", messageHandler.UpdateMessage).Methods("PUT")
+	authAPI.HandleFunc("/rooms/{roomId}/messages/{messageId}", messageHandler.DeleteMessage).Methods("DELETE")
+	authAPI.HandleFunc("/rooms/{roomId}/messages/pinned", messageHandler.GetPinnedMessages).Methods("GET")
+	authAPI.HandleFunc("/rooms/{roomId}/messages/{messageId}/pin", messageHandler.PinMessage).Methods("POST")
+	authAPI.HandleFunc("/rooms/{roomId}/messages/{messageId}/pin", messageHandler.UnpinMessage).Methods("DELETE")
+	authAPI.HandleFunc("/rooms/{roomId}/messages/{messageId}/thread", messageHandler.GetThread).Methods("GET")
+	authAPI.HandleFunc("/rooms/{roomId}/messages/{messageId}/revisions", messageHandler.GetMessageRevisions).Methods("GET")
+	authAPI.HandleFunc("/rooms/{roomId}/messages/{messageId}/reactions", messageHandler.AddReaction).Methods("POST")
+	authAPI.HandleFunc("/rooms/{roomId}/messages/{messageId}/reactions/{emoji}", messageHandler.RemoveReaction).Methods("DELETE")
+	authAPI.HandleFunc("/mentions", messageHandler.GetMentions).Methods("GET")
+	authAPI.HandleFunc("/agents", agentRegistryHandler.ListAgents).Methods("GET")
 	authAPI.HandleFunc("/rooms/{roomId}/update_last_read_for_user", messageHandler.UpdateLastRead).Methods("POST")
 	authAPI.HandleFunc("/rooms/{roomId}/attachments", attachmentHandler.UploadAttachment).Methods("POST")
 
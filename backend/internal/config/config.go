@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"time"
 
 	"github.com/caarlos0/env/v6"
 	"github.com/joho/godotenv"
@@ -16,6 +17,9 @@ type Config struct {
 	LiveKitAPIKey      string `env:"LIVEKIT_API_KEY,required"`
 	LiveKitAPISecret   string `env:"LIVEKIT_API_SECRET,required"`
 	LiveKitURL         string `env:"LIVEKIT_URL,required"`
+	// LiveKitTokenTTL is how long LiveKit tokens stay valid in rooms that do
+	// not set their own lifetime.
+	LiveKitTokenTTL    time.Duration `env:"LIVEKIT_TOKEN_TTL" envDefault:"24h"`
 	EmailProvider      string `env:"EMAIL_PROVIDER" envDefault:"sendgrid"`
 	SendGridAPIKey     string `env:"SENDGRID_API_KEY"`
 	SendGridFromEmail  string `env:"SENDGRID_FROM_EMAIL"`
@@ -28,6 +32,7 @@ type Config struct {
 	CORSAllowedOrigins string `env:"CORS_ALLOWED_ORIGINS,required"`
 	FromEmail          string `env:"FROM_EMAIL"`
 	FromName           string `env:"FROM_NAME"`
+	EventBusProvider   string `env:"EVENT_BUS_PROVIDER" envDefault:"postgres"`
 
 	StorageProvider  string `env:"STORAGE_PROVIDER" envDefault:"minio"`
 	StorageEndpoint  string `env:"STORAGE_ENDPOINT"`
@@ -37,10 +42,26 @@ type Config struct {
 	StorageRegion    string `env:"STORAGE_REGION"`
 	StorageGCSServiceAccountEmail string `env:"STORAGE_GCS_SERVICE_ACCOUNT_EMAIL"`
 
+	// Transcript storage: s3 (credentials from TRANSCRIPT_AWS_*), minio, gcs
+	// or local. minio and gcs share the attachment storage's connection.
+	// TRANSCRIPT_STORAGE_BUCKET applies to every bucket-based provider.
+	TranscriptStorageProvider string `env:"TRANSCRIPT_STORAGE_PROVIDER" envDefault:"s3"`
+	TranscriptStorageBucket   string `env:"TRANSCRIPT_STORAGE_BUCKET"`
+	TranscriptStorageLocalDir string `env:"TRANSCRIPT_STORAGE_LOCAL_DIR" envDefault:"./data/transcripts"`
+
 	TranscriptAWSAccessKeyID     string `env:"TRANSCRIPT_AWS_ACCESS_KEY_ID"`
 	TranscriptAWSSecretAccessKey string `env:"TRANSCRIPT_AWS_SECRET_ACCESS_KEY"`
 	TranscriptAWSRegion          string `env:"TRANSCRIPT_AWS_REGION"`
 	TranscriptAWSBucket          string `env:"TRANSCRIPT_AWS_BUCKET"`
+
+	// Default transcript redaction for rooms without their own policy.
+	// Patterns are name=regexp pairs separated by semicolons.
+	TranscriptRedactionEnabled    bool     `env:"TRANSCRIPT_REDACTION_ENABLED" envDefault:"true"`
+	TranscriptRedactionCategories []string `env:"TRANSCRIPT_REDACTION_CATEGORIES" envDefault:"email,phone,card"`
+	TranscriptRedactionPatterns   []string `env:"TRANSCRIPT_REDACTION_PATTERNS" envSeparator:";"`
+
+	AgentWebhookSecret    string        `env:"AGENT_WEBHOOK_SECRET,required"`
+	AgentWebhookTolerance time.Duration `env:"AGENT_WEBHOOK_TOLERANCE" envDefault:"5m"`
 }
 
 func Load() *Config {
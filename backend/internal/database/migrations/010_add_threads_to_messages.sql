@@ -0,0 +1,15 @@
+-- +migrate Up
+ALTER TABLE messages
+ADD COLUMN parent_message_id UUID REFERENCES messages(id) ON DELETE CASCADE,
+ADD COLUMN reply_count INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN last_reply_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_messages_parent_created ON messages(parent_message_id, created_at DESC);
+
+-- +migrate Down
+DROP INDEX idx_messages_parent_created;
+
+ALTER TABLE messages
+DROP COLUMN parent_message_id,
+DROP COLUMN reply_count,
+DROP COLUMN last_reply_at;
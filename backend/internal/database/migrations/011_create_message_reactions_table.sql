@@ -0,0 +1,27 @@
+-- +migrate Up
+CREATE TABLE message_reactions (
+    message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    emoji VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (message_id, user_id, emoji)
+);
+
+CREATE INDEX idx_message_reactions_message_id ON message_reactions(message_id, created_at);
+
+-- Move reactions previously kept in messages.metadata into the new table.
+INSERT INTO message_reactions (message_id, user_id, emoji)
+SELECT m.id, (uid #>> '{}')::uuid, reaction->>'emoji'
+FROM messages m
+CROSS JOIN LATERAL jsonb_array_elements(
+    CASE WHEN jsonb_typeof(m.metadata->'reactions') = 'array' THEN m.metadata->'reactions' ELSE '[]'::jsonb END
+) AS reaction
+CROSS JOIN LATERAL jsonb_array_elements(
+    CASE WHEN jsonb_typeof(reaction->'user_ids') = 'array' THEN reaction->'user_ids' ELSE '[]'::jsonb END
+) AS uid
+ON CONFLICT DO NOTHING;
+
+UPDATE messages SET metadata = metadata - 'reactions' WHERE metadata ? 'reactions';
+
+-- +migrate Down
+DROP TABLE message_reactions;
@@ -0,0 +1,15 @@
+-- +migrate Up
+CREATE TABLE message_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    editor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(20) NOT NULL, -- edit, delete
+    previous_content TEXT NOT NULL,
+    new_content TEXT, -- NULL for deletes
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_message_revisions_message_id ON message_revisions(message_id, created_at);
+
+-- +migrate Down
+DROP TABLE message_revisions;
@@ -0,0 +1,11 @@
+-- +migrate Up
+ALTER TABLE room_participants
+ADD COLUMN last_seen_at TIMESTAMP WITH TIME ZONE; -- refreshed while a realtime connection is open
+
+CREATE INDEX idx_messages_mentions ON messages USING GIN ((metadata->'mentions'));
+
+-- +migrate Down
+DROP INDEX idx_messages_mentions;
+
+ALTER TABLE room_participants
+DROP COLUMN last_seen_at;
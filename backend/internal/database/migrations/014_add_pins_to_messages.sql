@@ -0,0 +1,13 @@
+-- +migrate Up
+ALTER TABLE messages
+ADD COLUMN pinned_at TIMESTAMP WITH TIME ZONE,
+ADD COLUMN pinned_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_messages_room_pinned ON messages(room_id, pinned_at DESC) WHERE pinned_at IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX idx_messages_room_pinned;
+
+ALTER TABLE messages
+DROP COLUMN pinned_at,
+DROP COLUMN pinned_by;
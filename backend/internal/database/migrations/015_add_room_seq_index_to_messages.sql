@@ -0,0 +1,5 @@
+-- +migrate Up
+CREATE INDEX idx_messages_room_seq ON messages(room_id, seq_no);
+
+-- +migrate Down
+DROP INDEX idx_messages_room_seq;
@@ -0,0 +1,20 @@
+-- +migrate Up
+CREATE TABLE transcript_segments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE, -- the meeting_transcript message
+    segment_index INTEGER NOT NULL, -- position within the transcript, from 0
+    speaker_identity VARCHAR(255) NOT NULL DEFAULT '',
+    speaker_name VARCHAR(255) NOT NULL DEFAULT '',
+    role VARCHAR(50) NOT NULL DEFAULT '',
+    start_ms BIGINT NOT NULL, -- offset from the session start
+    end_ms BIGINT NOT NULL,
+    text TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(message_id, segment_index)
+);
+
+CREATE INDEX idx_transcript_segments_room_id ON transcript_segments(room_id);
+
+-- +migrate Down
+DROP TABLE transcript_segments;
@@ -0,0 +1,5 @@
+-- +migrate Up
+CREATE INDEX idx_transcript_segments_text_search ON transcript_segments USING GIN (to_tsvector('english', text));
+
+-- +migrate Down
+DROP INDEX idx_transcript_segments_text_search;
@@ -0,0 +1,11 @@
+-- +migrate Up
+ALTER TABLE messages
+ADD COLUMN idempotency_key TEXT; -- set by webhook deliveries so retries do not duplicate messages
+
+CREATE UNIQUE INDEX idx_messages_idempotency_key ON messages(idempotency_key) WHERE idempotency_key IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX idx_messages_idempotency_key;
+
+ALTER TABLE messages
+DROP COLUMN idempotency_key;
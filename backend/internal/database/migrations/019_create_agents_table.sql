@@ -0,0 +1,30 @@
+-- +migrate Up
+CREATE TABLE agents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    identity VARCHAR(255) UNIQUE NOT NULL, -- stable identifier the agent reports, e.g. its LiveKit identity
+    display_name VARCHAR(255) NOT NULL,
+    avatar_url TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+INSERT INTO agents (identity, display_name) VALUES ('meeting-assistant', 'Meeting Assistant');
+
+ALTER TABLE messages
+ADD COLUMN agent_id UUID REFERENCES agents(id) ON DELETE SET NULL;
+
+-- Agent posts used to be attributed to the room owner.
+UPDATE messages
+SET agent_id = (SELECT id FROM agents WHERE identity = 'meeting-assistant'), user_id = NULL
+WHERE message_type IN ('meeting_transcript', 'meeting_summary', 'action_items', 'agent_joined', 'agent_left');
+
+-- +migrate Down
+UPDATE messages m
+SET user_id = r.owner_id
+FROM rooms r
+WHERE m.room_id = r.id AND m.agent_id IS NOT NULL;
+
+ALTER TABLE messages
+DROP COLUMN agent_id;
+
+DROP TABLE agents;
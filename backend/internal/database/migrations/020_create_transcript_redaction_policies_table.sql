@@ -0,0 +1,13 @@
+-- +migrate Up
+CREATE TABLE transcript_redaction_policies (
+    room_id UUID PRIMARY KEY REFERENCES rooms(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    categories TEXT[] NOT NULL DEFAULT '{}', -- built-in detectors: email, phone, card, ssn, ip
+    custom_patterns JSONB NOT NULL DEFAULT '[]', -- [{"name": "...", "pattern": "<RE2 regexp>"}]
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- +migrate Down
+DROP TABLE transcript_redaction_policies;
@@ -0,0 +1,31 @@
+-- +migrate Up
+ALTER TABLE transcript_segments
+ADD COLUMN version INTEGER NOT NULL DEFAULT 0, -- transcript version of the segment's last correction, 0 if never corrected
+ADD COLUMN edited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+ADD COLUMN edited_at TIMESTAMP WITH TIME ZONE;
+
+-- Every correction creates the next version of its transcript, so version N
+-- is the uploaded transcript with corrections 1..N applied.
+CREATE TABLE transcript_segment_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    segment_id UUID NOT NULL REFERENCES transcript_segments(id) ON DELETE CASCADE,
+    message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    editor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    previous_text TEXT NOT NULL,
+    new_text TEXT NOT NULL,
+    previous_speaker_name VARCHAR(255) NOT NULL,
+    new_speaker_name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(message_id, version)
+);
+
+CREATE INDEX idx_transcript_segment_revisions_segment ON transcript_segment_revisions(segment_id, version);
+
+-- +migrate Down
+DROP TABLE transcript_segment_revisions;
+
+ALTER TABLE transcript_segments
+DROP COLUMN version,
+DROP COLUMN edited_by,
+DROP COLUMN edited_at;
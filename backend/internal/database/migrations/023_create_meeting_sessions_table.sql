@@ -0,0 +1,32 @@
+-- +migrate Up
+CREATE TABLE meeting_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    room_sid VARCHAR(255) UNIQUE NOT NULL, -- LiveKit SID of the call, new for every call in the room
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ended_at TIMESTAMP WITH TIME ZONE,
+    transcript_message_id UUID REFERENCES messages(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_meeting_sessions_room_started ON meeting_sessions(room_id, started_at DESC);
+
+-- One row per stay in the call; a participant who drops and rejoins has
+-- several.
+CREATE TABLE meeting_attendance (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_id UUID NOT NULL REFERENCES meeting_sessions(id) ON DELETE CASCADE,
+    participant_id UUID REFERENCES room_participants(id) ON DELETE SET NULL,
+    identity VARCHAR(255) NOT NULL, -- LiveKit participant identity
+    name VARCHAR(255) NOT NULL,
+    joined_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    left_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE(session_id, identity, joined_at)
+);
+
+CREATE INDEX idx_meeting_attendance_participant ON meeting_attendance(participant_id);
+
+-- +migrate Down
+DROP TABLE meeting_attendance;
+DROP TABLE meeting_sessions;
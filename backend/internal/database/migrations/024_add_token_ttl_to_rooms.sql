@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE rooms
+ADD COLUMN token_ttl_seconds INTEGER; -- NULL uses LIVEKIT_TOKEN_TTL
+
+-- +migrate Down
+ALTER TABLE rooms
+DROP COLUMN token_ttl_seconds;
@@ -0,0 +1,20 @@
+-- +migrate Up
+-- Guests joining through an invite wait here until a host admits them.
+CREATE TABLE lobby_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    invite_id UUID UNIQUE NOT NULL REFERENCES invites(id) ON DELETE CASCADE,
+    participant_id UUID REFERENCES room_participants(id) ON DELETE SET NULL,
+    email VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, approved, denied
+    decided_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_lobby_requests_room_status ON lobby_requests(room_id, status, created_at);
+
+-- +migrate Down
+DROP TABLE lobby_requests;
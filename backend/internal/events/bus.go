@@ -0,0 +1,19 @@
+package events
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Publisher sends room events to every API instance.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// Bus is a room-scoped publish/subscribe channel shared by all API instances.
+type Bus interface {
+	Publisher
+	Subscribe(roomID uuid.UUID) *Subscription
+	Close() error
+}
@@ -0,0 +1,105 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Type identifies what happened in a room.
+type Type string
+
+const (
+	// MessageCreated is emitted after a message has been stored in a room.
+	MessageCreated Type = "message.created"
+	// MessageUpdated is emitted after a message's content or metadata has changed.
+	MessageUpdated Type = "message.updated"
+	// MessageDeleted is emitted after a message has been soft deleted.
+	MessageDeleted Type = "message.deleted"
+	// MessageReactionAdded is emitted after a user reacted to a message.
+	MessageReactionAdded Type = "message.reaction_added"
+	// MessageReactionRemoved is emitted after a user withdrew a reaction.
+	MessageReactionRemoved Type = "message.reaction_removed"
+	// ParticipantAdded is emitted after a participant has been added to a room.
+	ParticipantAdded Type = "participant.added"
+	// ParticipantRemoved is emitted after a participant has been removed from a room.
+	ParticipantRemoved Type = "participant.removed"
+	// RoomUpdated is emitted after a room's details have changed.
+	RoomUpdated Type = "room.updated"
+	// RoomDeleted is emitted after a room has been deleted.
+	RoomDeleted Type = "room.deleted"
+	// LiveCaption carries a caption produced by the meeting agent. Captions
+	// are only relayed, never stored.
+	LiveCaption Type = "agent.live_caption"
+	// MeetingStarted is emitted when LiveKit reports the room's call started.
+	MeetingStarted Type = "meeting.started"
+	// MeetingFinished is emitted when LiveKit reports the room's call ended.
+	MeetingFinished Type = "meeting.finished"
+	// MeetingParticipantJoined is emitted when someone joins the call.
+	MeetingParticipantJoined Type = "meeting.participant_joined"
+	// MeetingParticipantLeft is emitted when someone leaves the call.
+	MeetingParticipantLeft Type = "meeting.participant_left"
+	// MeetingEgressEnded is emitted when a recording or stream of the call
+	// has finished.
+	MeetingEgressEnded Type = "meeting.egress_ended"
+	// LobbyRequested is emitted when a guest starts waiting in the lobby.
+	// Lobby events are only delivered to the room's hosts.
+	LobbyRequested Type = "lobby.requested"
+	// LobbyApproved is emitted when a host admits a guest.
+	LobbyApproved Type = "lobby.approved"
+	// LobbyDenied is emitted when a host turns a guest away.
+	LobbyDenied Type = "lobby.denied"
+	// ReplayTruncated is sent instead of a replay to a reconnecting client
+	// that missed too many messages; it should reload the room's messages.
+	// It is never published on the bus.
+	ReplayTruncated Type = "replay.truncated"
+)
+
+// HostsOnly reports whether events of this type are only for the room's
+// owner and moderators.
+func (t Type) HostsOnly() bool {
+	switch t {
+	case LobbyRequested, LobbyApproved, LobbyDenied:
+		return true
+	}
+	return false
+}
+
+// Event is a single room activity notification. It deliberately carries only
+// identifiers plus a small optional payload so it can be fanned out cheaply;
+// consumers load the full records they need.
+type Event struct {
+	Type      Type            `json:"type"`
+	RoomID    uuid.UUID       `json:"room_id"`
+	MessageID *uuid.UUID      `json:"message_id,omitempty"`
+	SeqNo     int             `json:"seq_no,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// NewMessageEvent builds an event that refers to a single message.
+func NewMessageEvent(eventType Type, roomID, messageID uuid.UUID, seqNo int) Event {
+	return Event{
+		Type:      eventType,
+		RoomID:    roomID,
+		MessageID: &messageID,
+		SeqNo:     seqNo,
+		CreatedAt: time.Now(),
+	}
+}
+
+// NewRoomEvent builds an event carrying data as its JSON payload.
+func NewRoomEvent(eventType Type, roomID uuid.UUID, data interface{}) Event {
+	event := Event{
+		Type:      eventType,
+		RoomID:    roomID,
+		CreatedAt: time.Now(),
+	}
+	if data != nil {
+		// Room records always marshal; on failure the event still goes out
+		// and subscribers reload the room.
+		event.Data, _ = json.Marshal(data)
+	}
+	return event
+}
@@ -0,0 +1,101 @@
+package events
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// subscriptionBuffer is how many events a subscriber may fall behind before
+// it is considered too slow and disconnected.
+const subscriptionBuffer = 64
+
+// MemoryBus fans out room events to subscribers in the current process only.
+// It is used directly in tests and single-instance setups, and as the local
+// delivery stage of PostgresBus.
+type MemoryBus struct {
+	mu   sync.RWMutex
+	subs map[uuid.UUID]map[*Subscription]struct{}
+}
+
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{
+		subs: make(map[uuid.UUID]map[*Subscription]struct{}),
+	}
+}
+
+// Subscription receives the events published for a single room. The channel
+// is closed when the subscription is closed or when the subscriber lags too
+// far behind, in which case clients are expected to reconnect and resume.
+type Subscription struct {
+	C      <-chan Event
+	ch     chan Event
+	roomID uuid.UUID
+	bus    *MemoryBus
+	once   sync.Once
+}
+
+// Subscribe registers a new subscriber for the given room.
+func (b *MemoryBus) Subscribe(roomID uuid.UUID) *Subscription {
+	ch := make(chan Event, subscriptionBuffer)
+	sub := &Subscription{C: ch, ch: ch, roomID: roomID, bus: b}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subs[roomID] == nil {
+		b.subs[roomID] = make(map[*Subscription]struct{})
+	}
+	b.subs[roomID][sub] = struct{}{}
+
+	return sub
+}
+
+// Publish delivers the event to every subscriber of the event's room.
+func (b *MemoryBus) Publish(ctx context.Context, event Event) error {
+	b.mu.RLock()
+	var lagging []*Subscription
+	for sub := range b.subs[event.RoomID] {
+		select {
+		case sub.ch <- event:
+		default:
+			lagging = append(lagging, sub)
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, sub := range lagging {
+		sub.Close()
+	}
+	return nil
+}
+
+// Close disconnects every subscriber.
+func (b *MemoryBus) Close() error {
+	b.mu.RLock()
+	var all []*Subscription
+	for _, subs := range b.subs {
+		for sub := range subs {
+			all = append(all, sub)
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, sub := range all {
+		sub.Close()
+	}
+	return nil
+}
+
+// Close unregisters the subscription and closes its channel.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.bus.mu.Lock()
+		defer s.bus.mu.Unlock()
+		delete(s.bus.subs[s.roomID], s)
+		if len(s.bus.subs[s.roomID]) == 0 {
+			delete(s.bus.subs, s.roomID)
+		}
+		close(s.ch)
+	})
+}
@@ -0,0 +1,103 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// notifyChannel is the Postgres channel all room events are sent on.
+	notifyChannel = "room_events"
+	// maxNotifyPayload stays below Postgres' 8000 byte NOTIFY payload limit.
+	maxNotifyPayload = 7900
+)
+
+// PostgresBus distributes room events between API instances with Postgres
+// LISTEN/NOTIFY. Every instance listens on a single channel and hands the
+// events it receives to its local subscribers.
+type PostgresBus struct {
+	db       *sqlx.DB
+	listener *pq.Listener
+	local    *MemoryBus
+}
+
+func NewPostgresBus(db *sqlx.DB, databaseURL string) (*PostgresBus, error) {
+	listener := pq.NewListener(databaseURL, 10*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Error().Err(err).Msg("Event bus listener connection problem")
+		}
+	})
+	if err := listener.Listen(notifyChannel); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	b := &PostgresBus{
+		db:       db,
+		listener: listener,
+		local:    NewMemoryBus(),
+	}
+	go b.run()
+
+	return b, nil
+}
+
+// Publish sends the event to every instance, including this one, through
+// pg_notify. Oversized payloads are dropped from the event; subscribers load
+// the referenced records themselves.
+func (b *PostgresBus) Publish(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxNotifyPayload {
+		event.Data = nil
+		if payload, err = json.Marshal(event); err != nil {
+			return err
+		}
+	}
+
+	_, err = b.db.ExecContext(ctx, "SELECT pg_notify($1, $2)", notifyChannel, string(payload))
+	return err
+}
+
+func (b *PostgresBus) Subscribe(roomID uuid.UUID) *Subscription {
+	return b.local.Subscribe(roomID)
+}
+
+func (b *PostgresBus) Close() error {
+	err := b.listener.Close()
+	b.local.Close()
+	return err
+}
+
+func (b *PostgresBus) run() {
+	for {
+		select {
+		case n, ok := <-b.listener.Notify:
+			if !ok {
+				return
+			}
+			if n == nil {
+				// The connection was re-established; anything sent while it
+				// was down is lost and clients catch up by seq_no.
+				continue
+			}
+
+			var event Event
+			if err := json.Unmarshal([]byte(n.Extra), &event); err != nil {
+				log.Error().Err(err).Msg("Failed to decode room event notification")
+				continue
+			}
+			b.local.Publish(context.Background(), event)
+		case <-time.After(90 * time.Second):
+			go b.listener.Ping()
+		}
+	}
+}
@@ -2,6 +2,8 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 
 	"livekit-consulting/backend/internal/service"
@@ -10,12 +12,12 @@ import (
 )
 
 type AgentWebhookHandler struct {
-	messageService *service.MessageService
+	dispatcher *service.AgentEventDispatcher
 }
 
-func NewAgentWebhookHandler(messageService *service.MessageService) *AgentWebhookHandler {
+func NewAgentWebhookHandler(dispatcher *service.AgentEventDispatcher) *AgentWebhookHandler {
 	return &AgentWebhookHandler{
-		messageService: messageService,
+		dispatcher: dispatcher,
 	}
 }
 
@@ -25,29 +27,40 @@ func (h *AgentWebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	var payload service.AgentWebhookPayload
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
 		http.Error(w, "Invalid request payload", http.StatusBadRequest)
 		return
 	}
 
-	// Validate payload if necessary (e.g., check event type, room name)
-	if payload.Event != "transcript_uploaded" {
-		http.Error(w, "Unsupported event type", http.StatusBadRequest)
+	result, err := h.dispatcher.Dispatch(r.Context(), body)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnsupportedAgentEvent), errors.Is(err, service.ErrInvalidAgentEvent):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, service.ErrRoomNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			log.Error().
+				Err(err).
+				Msg("Failed to process agent webhook")
+			http.Error(w, "Failed to process agent webhook: "+err.Error(), http.StatusInternalServerError)
+		}
 		return
 	}
 
-	_, err := h.messageService.CreateTranscriptMessage(r.Context(), &payload)
-	if err != nil {
-		log.Error().
-			Err(err).
-			Str("room_name", payload.RoomName).
-			Str("event", payload.Event).
-			Msg("Failed to process agent webhook")
-		http.Error(w, "Failed to create transcript message: "+err.Error(), http.StatusInternalServerError)
-		return
+	if result.Duplicate {
+		log.Info().
+			Str("message_id", result.Message.ID.String()).
+			Msg("Ignoring repeated agent webhook delivery")
 	}
 
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"status": "success", "message": "Transcript webhook processed"})
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":    "success",
+		"message":   "Agent webhook processed",
+		"duplicate": result.Duplicate,
+		"data":      result.Message,
+	})
 }
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"net/http"
+
+	"livekit-consulting/backend/internal/service"
+)
+
+// AgentRegistryHandler exposes the bot principals that author agent
+// messages, so clients can render their names and avatars.
+type AgentRegistryHandler struct {
+	agentService *service.AgentService
+}
+
+func NewAgentRegistryHandler(agentService *service.AgentService) *AgentRegistryHandler {
+	return &AgentRegistryHandler{agentService: agentService}
+}
+
+func (h *AgentRegistryHandler) ListAgents(w http.ResponseWriter, r *http.Request) {
+	agents, err := h.agentService.ListAgents(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, agents)
+}
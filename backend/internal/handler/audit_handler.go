@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"livekit-consulting/backend/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// GetRoomAuditLog pages through the room's audit log, newest first. Pass the
+// created_at of the last entry received as before to load the next page.
+func (h *AuditHandler) GetRoomAuditLog(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomID, err := uuid.Parse(vars["roomId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid room ID")
+		return
+	}
+
+	userID, err := getUserIDFromContext(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	limit := 50
+	if l := r.URL.Query().Get("limit"); l != "" {
+		limit, _ = strconv.Atoi(l)
+	}
+
+	var before *time.Time
+	if b := r.URL.Query().Get("before"); b != "" {
+		t, err := time.Parse(time.RFC3339Nano, b)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid before")
+			return
+		}
+		before = &t
+	}
+
+	entries, err := h.auditService.GetRoomAuditLog(r.Context(), roomID, userID, limit, before)
+	if err != nil {
+		respondWithMessageError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, entries)
+}
+
+// auditActorFromRequest identifies the user behind a request for the audit
+// log. Behind a load balancer the client address is the first entry of
+// X-Forwarded-For.
+func auditActorFromRequest(r *http.Request, userID uuid.UUID) service.AuditActor {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		ip = strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+
+	return service.AuditActor{
+		UserID:    userID,
+		IPAddress: ip,
+		UserAgent: r.UserAgent(),
+	}
+}
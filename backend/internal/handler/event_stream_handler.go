@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"livekit-consulting/backend/internal/events"
+	"livekit-consulting/backend/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	eventStreamKeepAlive = 25 * time.Second
+	eventStreamRetry     = 3000 // milliseconds
+)
+
+// EventStreamHandler serves room activity as Server-Sent Events for clients
+// whose network does not allow WebSocket upgrades.
+type EventStreamHandler struct {
+	realtimeService *service.RealtimeService
+}
+
+func NewEventStreamHandler(realtimeService *service.RealtimeService) *EventStreamHandler {
+	return &EventStreamHandler{realtimeService: realtimeService}
+}
+
+// ServeRoomEvents streams the same events as the room WebSocket. Every event
+// id is the room's last_message_seq as seen by the stream, so the browser's
+// automatic Last-Event-ID on reconnect resumes exactly where it stopped.
+// Clients that open a fresh stream may pass last_event_id instead.
+func (h *EventStreamHandler) ServeRoomEvents(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomID, err := uuid.Parse(vars["roomId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid room ID")
+		return
+	}
+
+	userID, err := getUserIDFromContext(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+	sinceSeq := -1
+	if lastEventID != "" {
+		sinceSeq, err = strconv.Atoi(lastEventID)
+		if err != nil || sinceSeq < 0 {
+			respondWithError(w, http.StatusBadRequest, "invalid Last-Event-ID")
+			return
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		respondWithError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	if err := h.realtimeService.Authorize(r.Context(), roomID, userID); err != nil {
+		if errors.Is(err, service.ErrNotRoomMember) {
+			respondWithError(w, http.StatusForbidden, err.Error())
+		} else {
+			respondWithError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	sub := h.realtimeService.Subscribe(roomID)
+	defer sub.Close()
+
+	lastSeq := sinceSeq
+	var replay []*service.RoomEvent
+	if sinceSeq >= 0 {
+		replay, err = h.realtimeService.Replay(r.Context(), roomID, sinceSeq)
+	} else {
+		lastSeq, err = h.realtimeService.LastSeq(r.Context(), roomID)
+	}
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("room_id", roomID.String()).
+			Msg("Failed to prepare room event stream")
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", eventStreamRetry)
+	for _, event := range replay {
+		lastSeq = event.SeqNo
+		if err := writeServerSentEvent(w, lastSeq, event); err != nil {
+			return
+		}
+	}
+	// Tell the client where the stream starts, which also seeds its
+	// Last-Event-ID when nothing has been replayed.
+	fmt.Fprintf(w, "id: %d\nevent: ready\ndata: {\"last_message_seq\":%d}\n\n", lastSeq, lastSeq)
+	flusher.Flush()
+
+	touchPresence(r, h.realtimeService, roomID, userID)
+
+	ticker := time.NewTicker(eventStreamKeepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+			if !stillAuthorized(r, h.realtimeService, roomID, userID) {
+				return
+			}
+			touchPresence(r, h.realtimeService, roomID, userID)
+		case event, ok := <-sub.C:
+			if !ok {
+				// We fell too far behind; the browser reconnects with
+				// Last-Event-ID and replays what it missed.
+				return
+			}
+			if event.Type == events.ParticipantRemoved && !stillAuthorized(r, h.realtimeService, roomID, userID) {
+				return
+			}
+			if event.Type == events.MessageCreated {
+				if event.SeqNo <= lastSeq {
+					continue
+				}
+				lastSeq = event.SeqNo
+			}
+
+			if allowed, err := h.realtimeService.CanReceive(r.Context(), userID, event); err != nil || !allowed {
+				continue
+			}
+
+			roomEvent, err := h.realtimeService.Resolve(r.Context(), event)
+			if err != nil {
+				log.Error().
+					Err(err).
+					Str("room_id", roomID.String()).
+					Str("event", string(event.Type)).
+					Msg("Failed to resolve room event")
+				continue
+			}
+
+			if err := writeServerSentEvent(w, lastSeq, roomEvent); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeServerSentEvent(w http.ResponseWriter, id int, event *service.RoomEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", id, event.Type, data)
+	return err
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"livekit-consulting/backend/internal/service"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/webhook"
+	"github.com/rs/zerolog/log"
+)
+
+// LiveKitWebhookHandler receives the webhooks LiveKit server sends about
+// rooms, participants and egress.
+type LiveKitWebhookHandler struct {
+	webhookService *service.LiveKitWebhookService
+	keyProvider    auth.KeyProvider
+}
+
+func NewLiveKitWebhookHandler(webhookService *service.LiveKitWebhookService, apiKey, apiSecret string) *LiveKitWebhookHandler {
+	return &LiveKitWebhookHandler{
+		webhookService: webhookService,
+		keyProvider:    auth.NewSimpleKeyProvider(apiKey, apiSecret),
+	}
+}
+
+func (h *LiveKitWebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
+	// LiveKit signs each webhook with a JWT from our API key that carries a
+	// hash of the body.
+	event, err := webhook.ReceiveWebhookEvent(r, h.keyProvider)
+	if err != nil {
+		log.Warn().
+			Err(err).
+			Msg("Rejected LiveKit webhook")
+		http.Error(w, "Invalid webhook", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.webhookService.HandleEvent(r.Context(), event); err != nil {
+		log.Error().
+			Err(err).
+			Str("event", event.GetEvent()).
+			Str("event_id", event.GetId()).
+			Msg("Failed to process LiveKit webhook")
+		http.Error(w, "Failed to process LiveKit webhook: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"event":  event.GetEvent(),
+	})
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"livekit-consulting/backend/internal/model"
+	"livekit-consulting/backend/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type LobbyHandler struct {
+	lobbyService *service.LobbyService
+}
+
+func NewLobbyHandler(lobbyService *service.LobbyService) *LobbyHandler {
+	return &LobbyHandler{lobbyService: lobbyService}
+}
+
+// JoinRoom lets a guest with an invite token join the room's call. Until a
+// host admits them it answers 202 with status pending, and guests call it
+// again to check; once admitted it returns their LiveKit token.
+func (h *LobbyHandler) JoinRoom(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomID, err := uuid.Parse(vars["roomId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid room ID")
+		return
+	}
+
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		respondWithError(w, http.StatusBadRequest, "Missing join token")
+		return
+	}
+
+	res, err := h.lobbyService.JoinExternal(r.Context(), roomID, token)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInvite) {
+			respondWithError(w, http.StatusForbidden, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	switch res.Status {
+	case model.LobbyStatusApproved:
+		respondWithJSON(w, http.StatusOK, res)
+	case model.LobbyStatusDenied:
+		respondWithJSON(w, http.StatusForbidden, res)
+	default:
+		respondWithJSON(w, http.StatusAccepted, res)
+	}
+}
+
+// ListPending returns the guests waiting in the room's lobby.
+func (h *LobbyHandler) ListPending(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomID, err := uuid.Parse(vars["roomId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid room ID")
+		return
+	}
+
+	userID, err := getUserIDFromContext(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	requests, err := h.lobbyService.ListPending(r.Context(), roomID, userID)
+	if err != nil {
+		respondWithLobbyError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, requests)
+}
+
+func (h *LobbyHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.lobbyService.Approve)
+}
+
+func (h *LobbyHandler) Deny(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.lobbyService.Deny)
+}
+
+func (h *LobbyHandler) decide(w http.ResponseWriter, r *http.Request, decide func(ctx context.Context, roomID, requestID uuid.UUID, actor service.AuditActor) (*model.LobbyRequest, error)) {
+	vars := mux.Vars(r)
+	roomID, err := uuid.Parse(vars["roomId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid room ID")
+		return
+	}
+	requestID, err := uuid.Parse(vars["requestId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request ID")
+		return
+	}
+
+	userID, err := getUserIDFromContext(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	request, err := decide(r.Context(), roomID, requestID, auditActorFromRequest(r, userID))
+	if err != nil {
+		respondWithLobbyError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, request)
+}
+
+func respondWithLobbyError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrLobbyRequestNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrLobbyRequestDecided):
+		respondWithError(w, http.StatusConflict, err.Error())
+	default:
+		respondWithMessageError(w, err)
+	}
+}
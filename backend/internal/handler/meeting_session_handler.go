@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"livekit-consulting/backend/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type MeetingSessionHandler struct {
+	sessionService *service.MeetingSessionService
+}
+
+func NewMeetingSessionHandler(sessionService *service.MeetingSessionService) *MeetingSessionHandler {
+	return &MeetingSessionHandler{sessionService: sessionService}
+}
+
+// ListSessions pages through the room's meeting sessions, latest first. Pass
+// the started_at of the last session received as before to load the next
+// page.
+func (h *MeetingSessionHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomID, err := uuid.Parse(vars["roomId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid room ID")
+		return
+	}
+
+	userID, err := getUserIDFromContext(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	limit := 20
+	if l := r.URL.Query().Get("limit"); l != "" {
+		limit, _ = strconv.Atoi(l)
+	}
+
+	var before *time.Time
+	if b := r.URL.Query().Get("before"); b != "" {
+		t, err := time.Parse(time.RFC3339Nano, b)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid before")
+			return
+		}
+		before = &t
+	}
+
+	sessions, err := h.sessionService.ListSessions(r.Context(), roomID, userID, limit, before)
+	if err != nil {
+		respondWithMessageError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, sessions)
+}
+
+// GetSession returns a meeting session with the attendance of everyone who
+// joined it.
+func (h *MeetingSessionHandler) GetSession(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomID, err := uuid.Parse(vars["roomId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid room ID")
+		return
+	}
+	sessionID, err := uuid.Parse(vars["sessionId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid session ID")
+		return
+	}
+
+	userID, err := getUserIDFromContext(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	session, err := h.sessionService.GetSession(r.Context(), roomID, sessionID, userID)
+	if err != nil {
+		respondWithMessageError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, session)
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -49,7 +50,14 @@ func (h *MessageHandler) CreateMessage(w http.ResponseWriter, r *http.Request) {
 
 	message, err := h.messageService.CreateMessage(r.Context(), &req, roomID, userID)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		switch {
+		case errors.Is(err, service.ErrNotRoomMember):
+			respondWithError(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, service.ErrMessageNotFound):
+			respondWithError(w, http.StatusNotFound, "parent message not found")
+		default:
+			respondWithError(w, http.StatusInternalServerError, err.Error())
+		}
 		return
 	}
 
@@ -75,16 +83,43 @@ func (h *MessageHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
 		limit, _ = strconv.Atoi(l)
 	}
 
-	var before *uuid.UUID
+	var cursor model.MessageCursor
 	if b := r.URL.Query().Get("before"); b != "" {
 		beforeID, err := uuid.Parse(b)
 		if err == nil {
-			before = &beforeID
+			cursor.Before = &beforeID
+		}
+	}
+
+	cursorParams := 0
+	for name, seq := range map[string]**int{
+		"before_seq": &cursor.BeforeSeq,
+		"after_seq":  &cursor.AfterSeq,
+		"around_seq": &cursor.AroundSeq,
+	} {
+		v := r.URL.Query().Get(name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			respondWithError(w, http.StatusBadRequest, "invalid "+name)
+			return
 		}
+		*seq = &n
+		cursorParams++
+	}
+	if cursorParams > 1 {
+		respondWithError(w, http.StatusBadRequest, "only one of before_seq, after_seq and around_seq may be set")
+		return
 	}
 
-	messages, err := h.messageService.GetMessages(r.Context(), roomID, userID, limit, before)
+	messages, err := h.messageService.GetMessages(r.Context(), roomID, userID, limit, cursor)
 	if err != nil {
+		if errors.Is(err, service.ErrNotRoomMember) {
+			respondWithError(w, http.StatusForbidden, err.Error())
+			return
+		}
 		log.Error().
 			Err(err).
 			Str("room_id", roomID.String()).
@@ -97,8 +132,91 @@ func (h *MessageHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, messages)
 }
 
+// GetMentions lists the messages that mention the current user across all of
+// their rooms.
+func (h *MessageHandler) GetMentions(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserIDFromContext(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	limit := 20
+	if l := r.URL.Query().Get("limit"); l != "" {
+		limit, _ = strconv.Atoi(l)
+	}
+
+	var before *uuid.UUID
+	if b := r.URL.Query().Get("before"); b != "" {
+		beforeID, err := uuid.Parse(b)
+		if err == nil {
+			before = &beforeID
+		}
+	}
+
+	messages, err := h.messageService.GetMentions(r.Context(), userID, limit, before)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("user_id", userID.String()).
+			Msg("Failed to get mentions")
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, messages)
+}
+
+func (h *MessageHandler) GetThread(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomID, err := uuid.Parse(vars["roomId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid room ID")
+		return
+	}
+
+	messageID, err := uuid.Parse(vars["messageId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid message ID")
+		return
+	}
+
+	userID, err := getUserIDFromContext(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	limit := 20
+	if l := r.URL.Query().Get("limit"); l != "" {
+		limit, _ = strconv.Atoi(l)
+	}
+
+	var before *uuid.UUID
+	if b := r.URL.Query().Get("before"); b != "" {
+		beforeID, err := uuid.Parse(b)
+		if err == nil {
+			before = &beforeID
+		}
+	}
+
+	thread, err := h.messageService.GetThread(r.Context(), roomID, messageID, userID, limit, before)
+	if err != nil {
+		respondWithMessageError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, thread)
+}
+
 func (h *MessageHandler) UpdateMessage(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
+	roomID, err := uuid.Parse(vars["roomId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid room ID")
+		return
+	}
+
 	messageID, err := uuid.Parse(vars["messageId"])
 	if err != nil {
 		respondWithError(w, http.StatusBadRequest, "invalid message ID")
@@ -117,8 +235,13 @@ func (h *MessageHandler) UpdateMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.messageService.UpdateMessage(r.Context(), messageID, userID, req.Content); err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+	if err := utils.ValidateStruct(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.messageService.UpdateMessage(r.Context(), roomID, messageID, userID, req.Content); err != nil {
+		respondWithMessageError(w, err)
 		return
 	}
 
@@ -127,6 +250,12 @@ func (h *MessageHandler) UpdateMessage(w http.ResponseWriter, r *http.Request) {
 
 func (h *MessageHandler) DeleteMessage(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
+	roomID, err := uuid.Parse(vars["roomId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid room ID")
+		return
+	}
+
 	messageID, err := uuid.Parse(vars["messageId"])
 	if err != nil {
 		respondWithError(w, http.StatusBadRequest, "invalid message ID")
@@ -139,14 +268,233 @@ func (h *MessageHandler) DeleteMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.messageService.DeleteMessage(r.Context(), messageID, userID); err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+	if err := h.messageService.DeleteMessage(r.Context(), roomID, messageID, userID); err != nil {
+		respondWithMessageError(w, err)
 		return
 	}
 
 	respondWithJSON(w, http.StatusOK, map[string]string{"message": "message deleted successfully"})
 }
 
+func (h *MessageHandler) SearchMessages(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomID, err := uuid.Parse(vars["roomId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid room ID")
+		return
+	}
+
+	userID, err := getUserIDFromContext(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		respondWithError(w, http.StatusBadRequest, "search query is required")
+		return
+	}
+
+	limit := 20
+	if l := r.URL.Query().Get("limit"); l != "" {
+		limit, _ = strconv.Atoi(l)
+	}
+
+	messages, err := h.messageService.SearchMessages(r.Context(), roomID, userID, query, limit)
+	if err != nil {
+		respondWithMessageError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, messages)
+}
+
+func (h *MessageHandler) AddReaction(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomID, err := uuid.Parse(vars["roomId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid room ID")
+		return
+	}
+
+	messageID, err := uuid.Parse(vars["messageId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid message ID")
+		return
+	}
+
+	userID, err := getUserIDFromContext(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	var req model.ReactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reactions, err := h.messageService.AddReaction(r.Context(), roomID, messageID, userID, req.Emoji)
+	if err != nil {
+		respondWithMessageError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, reactions)
+}
+
+func (h *MessageHandler) RemoveReaction(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomID, err := uuid.Parse(vars["roomId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid room ID")
+		return
+	}
+
+	messageID, err := uuid.Parse(vars["messageId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid message ID")
+		return
+	}
+
+	userID, err := getUserIDFromContext(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	req := model.ReactionRequest{Emoji: vars["emoji"]}
+	if err := utils.ValidateStruct(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reactions, err := h.messageService.RemoveReaction(r.Context(), roomID, messageID, userID, req.Emoji)
+	if err != nil {
+		respondWithMessageError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, reactions)
+}
+
+func (h *MessageHandler) GetMessageRevisions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomID, err := uuid.Parse(vars["roomId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid room ID")
+		return
+	}
+
+	messageID, err := uuid.Parse(vars["messageId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid message ID")
+		return
+	}
+
+	userID, err := getUserIDFromContext(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	revisions, err := h.messageService.GetMessageRevisions(r.Context(), roomID, messageID, userID)
+	if err != nil {
+		respondWithMessageError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, revisions)
+}
+
+func (h *MessageHandler) PinMessage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomID, err := uuid.Parse(vars["roomId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid room ID")
+		return
+	}
+
+	messageID, err := uuid.Parse(vars["messageId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid message ID")
+		return
+	}
+
+	userID, err := getUserIDFromContext(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	message, err := h.messageService.PinMessage(r.Context(), roomID, messageID, userID)
+	if err != nil {
+		respondWithMessageError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, message)
+}
+
+func (h *MessageHandler) UnpinMessage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomID, err := uuid.Parse(vars["roomId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid room ID")
+		return
+	}
+
+	messageID, err := uuid.Parse(vars["messageId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid message ID")
+		return
+	}
+
+	userID, err := getUserIDFromContext(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	message, err := h.messageService.UnpinMessage(r.Context(), roomID, messageID, userID)
+	if err != nil {
+		respondWithMessageError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, message)
+}
+
+func (h *MessageHandler) GetPinnedMessages(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomID, err := uuid.Parse(vars["roomId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid room ID")
+		return
+	}
+
+	userID, err := getUserIDFromContext(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	messages, err := h.messageService.GetPinnedMessages(r.Context(), roomID, userID)
+	if err != nil {
+		respondWithMessageError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, messages)
+}
+
 func (h *MessageHandler) UpdateLastRead(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	roomID, err := uuid.Parse(vars["roomId"])
@@ -175,3 +523,20 @@ func (h *MessageHandler) UpdateLastRead(w http.ResponseWriter, r *http.Request)
 
 	respondWithJSON(w, http.StatusOK, map[string]string{"message": "last read updated"})
 }
+
+// respondWithMessageError maps message service errors to HTTP status codes.
+func respondWithMessageError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotRoomMember), errors.Is(err, service.ErrPermissionDenied):
+		respondWithError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrMessageNotFound),
+		errors.Is(err, service.ErrRoomNotFound),
+		errors.Is(err, service.ErrTranscriptFileNotFound),
+		errors.Is(err, service.ErrTranscriptSegmentNotFound),
+		errors.Is(err, service.ErrTranscriptVersionNotFound),
+		errors.Is(err, service.ErrMeetingSessionNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	default:
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"livekit-consulting/backend/internal/model"
+	"livekit-consulting/backend/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type ModerationHandler struct {
+	moderationService *service.ModerationService
+}
+
+func NewModerationHandler(moderationService *service.ModerationService) *ModerationHandler {
+	return &ModerationHandler{moderationService: moderationService}
+}
+
+// ListCallParticipants returns who is in the room's call, with the SIDs of
+// their tracks to mute.
+func (h *ModerationHandler) ListCallParticipants(w http.ResponseWriter, r *http.Request) {
+	roomID, userID, ok := moderationRequestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	participants, err := h.moderationService.ListCallParticipants(r.Context(), roomID, userID)
+	if err != nil {
+		respondWithModerationError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, participants)
+}
+
+func (h *ModerationHandler) MuteTrack(w http.ResponseWriter, r *http.Request) {
+	roomID, userID, ok := moderationRequestIDs(w, r)
+	if !ok {
+		return
+	}
+	vars := mux.Vars(r)
+
+	var req model.MuteTrackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	track, err := h.moderationService.MuteTrack(r.Context(), roomID, auditActorFromRequest(r, userID), vars["identity"], vars["trackSid"], req.Muted)
+	if err != nil {
+		respondWithModerationError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, track)
+}
+
+func (h *ModerationHandler) RemoveParticipant(w http.ResponseWriter, r *http.Request) {
+	roomID, userID, ok := moderationRequestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.moderationService.RemoveParticipant(r.Context(), roomID, auditActorFromRequest(r, userID), mux.Vars(r)["identity"])
+	if err != nil {
+		respondWithModerationError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ModerationHandler) UpdatePermissions(w http.ResponseWriter, r *http.Request) {
+	roomID, userID, ok := moderationRequestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	var req model.UpdateCallPermissionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.CanPublish == nil && req.CanSubscribe == nil {
+		respondWithError(w, http.StatusBadRequest, "can_publish or can_subscribe is required")
+		return
+	}
+
+	participant, err := h.moderationService.UpdatePermissions(r.Context(), roomID, auditActorFromRequest(r, userID), mux.Vars(r)["identity"], &req)
+	if err != nil {
+		respondWithModerationError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, participant)
+}
+
+func moderationRequestIDs(w http.ResponseWriter, r *http.Request) (roomID, userID uuid.UUID, ok bool) {
+	roomID, err := uuid.Parse(mux.Vars(r)["roomId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid room ID")
+		return roomID, userID, false
+	}
+
+	userID, err = getUserIDFromContext(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return roomID, userID, false
+	}
+	return roomID, userID, true
+}
+
+func respondWithModerationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrParticipantNotInCall):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrCallNotStarted):
+		respondWithError(w, http.StatusConflict, err.Error())
+	default:
+		respondWithMessageError(w, err)
+	}
+}
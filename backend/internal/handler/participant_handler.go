@@ -2,6 +2,7 @@ package handler
 
 import (
     "encoding/json"
+    "errors"
     "net/http"
     "livekit-consulting/backend/internal/model"
     "livekit-consulting/backend/internal/service"
@@ -46,6 +47,10 @@ func (h *ParticipantHandler) AddParticipant(w http.ResponseWriter, r *http.Reque
 
     participant, err := h.participantService.AddParticipant(r.Context(), roomID, inviterID, &req)
     if err != nil {
+        if errors.Is(err, service.ErrPermissionDenied) || errors.Is(err, service.ErrRoomNotFound) {
+            respondWithMessageError(w, err)
+            return
+        }
         respondWithError(w, http.StatusInternalServerError, err.Error())
         return
     }
@@ -93,29 +98,6 @@ func (h *ParticipantHandler) RemoveParticipant(w http.ResponseWriter, r *http.Re
     respondWithJSON(w, http.StatusOK, map[string]string{"message": "Participant removed successfully"})
 }
 
-func (h *ParticipantHandler) JoinRoom(w http.ResponseWriter, r *http.Request) {
-    vars := mux.Vars(r)
-    roomID, err := uuid.Parse(vars["roomId"])
-    if err != nil {
-        respondWithError(w, http.StatusBadRequest, "Invalid room ID")
-        return
-    }
-
-    token := r.URL.Query().Get("token")
-    if token == "" {
-        respondWithError(w, http.StatusBadRequest, "Missing join token")
-        return
-    }
-
-    livekitToken, err := h.participantService.GenerateParticipantToken(r.Context(), roomID, token)
-    if err != nil {
-        respondWithError(w, http.StatusForbidden, err.Error())
-        return
-    }
-
-    respondWithJSON(w, http.StatusOK, map[string]string{"livekit_token": livekitToken})
-}
-
 func (h *ParticipantHandler) JoinRoomInternal(w http.ResponseWriter, r *http.Request) {
     userID, err := getUserIDFromContext(r)
     if err != nil {
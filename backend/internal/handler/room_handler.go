@@ -110,6 +110,40 @@ func (h *RoomHandler) DeleteRoom(w http.ResponseWriter, r *http.Request) {
     })
 }
 
+func (h *RoomHandler) UpdateRoomSettings(w http.ResponseWriter, r *http.Request) {
+    userID, err := getUserIDFromContext(r)
+    if err != nil {
+        respondWithError(w, http.StatusUnauthorized, err.Error())
+        return
+    }
+
+    vars := mux.Vars(r)
+    roomID, err := uuid.Parse(vars["roomId"])
+    if err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid room ID")
+        return
+    }
+
+    var req model.UpdateRoomSettingsRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid request")
+        return
+    }
+
+    if err := utils.ValidateStruct(&req); err != nil {
+        respondWithError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    room, err := h.roomService.UpdateRoomSettings(r.Context(), roomID, userID, &req)
+    if err != nil {
+        respondWithMessageError(w, err)
+        return
+    }
+
+    respondWithJSON(w, http.StatusOK, room)
+}
+
 func (h *RoomHandler) CreateRoomAtLiveKit(w http.ResponseWriter, r *http.Request) {
     userID, err := getUserIDFromContext(r)
     if err != nil {
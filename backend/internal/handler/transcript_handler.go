@@ -2,29 +2,37 @@ package handler
 
 import (
 	"encoding/json"
-	"io"
+	"errors"
+	"mime"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"livekit-consulting/backend/internal/model"
-	"livekit-consulting/backend/internal/repository"
 	"livekit-consulting/backend/internal/service"
+	"livekit-consulting/backend/internal/utils"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
 )
 
 type TranscriptHandler struct {
-	messageRepo         repository.MessageRepository
-	s3TranscriptStorage service.S3TranscriptStorage
+	transcriptService *service.TranscriptService
+	redactionService  *service.RedactionService
 }
 
-func NewTranscriptHandler(messageRepo repository.MessageRepository, s3TranscriptStorage service.S3TranscriptStorage) *TranscriptHandler {
+func NewTranscriptHandler(transcriptService *service.TranscriptService, redactionService *service.RedactionService) *TranscriptHandler {
 	return &TranscriptHandler{
-		messageRepo:         messageRepo,
-		s3TranscriptStorage: s3TranscriptStorage,
+		transcriptService: transcriptService,
+		redactionService:  redactionService,
 	}
 }
 
+// GetTranscript serves one of a transcript message's files with the latest
+// corrections applied, redacted according to the room's policy. Pass version
+// to get an older version; version=0 is the file as uploaded. The room owner
+// may pass original=true to get the file unredacted, which is audit-logged.
 func (h *TranscriptHandler) GetTranscript(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	roomIDStr := vars["roomId"]
@@ -42,39 +50,388 @@ func (h *TranscriptHandler) GetTranscript(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Fetch the message to verify it's a transcript message and belongs to the room.
-	message, err := h.messageRepo.GetByID(r.Context(), messageID)
+	userID, err := getUserIDFromContext(r)
 	if err != nil {
-		http.Error(w, "Message not found or database error", http.StatusInternalServerError)
+		respondWithError(w, http.StatusUnauthorized, err.Error())
 		return
 	}
-	if message == nil || message.RoomID != roomID || message.MessageType != model.MessageTypeMeetingTranscript || message.ExtraData == nil || message.ExtraData.Transcript == nil {
-		http.Error(w, "Transcript message not found or invalid", http.StatusNotFound)
+
+	original := false
+	if o := r.URL.Query().Get("original"); o != "" {
+		if original, err = strconv.ParseBool(o); err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid original")
+			return
+		}
+	}
+
+	var version *int
+	if v := r.URL.Query().Get("version"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid version")
+			return
+		}
+		version = &n
+	}
+
+	format, ok, err := negotiateTranscriptFormat(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if ok && format != service.TranscriptFormatJSON && !original && version == nil {
+		h.writeTranscriptExport(w, r, roomID, messageID, format)
 		return
 	}
 
-	// Validate that the requested s3KeyPath matches one of the keys in the database for that message.
-	// This prevents users from trying to access other S3 files.
-	dbS3KeyJSON := message.ExtraData.Transcript.S3Keys.JSON
-	dbS3KeyText := message.ExtraData.Transcript.S3Keys.Text
+	content, err := h.transcriptService.GetTranscriptFile(r.Context(), roomID, messageID, s3KeyPath, version, auditActorFromRequest(r, userID), original)
+	if err != nil {
+		if errors.Is(err, service.ErrTranscriptKeyMismatch) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Error().
+			Err(err).
+			Str("room_id", roomID.String()).
+			Str("message_id", messageID.String()).
+			Bool("original", original).
+			Msg("Failed to retrieve transcript file")
+		respondWithMessageError(w, err)
+		return
+	}
 
-	if s3KeyPath != dbS3KeyJSON && s3KeyPath != dbS3KeyText {
-		http.Error(w, "Invalid S3 key path for the given message", http.StatusBadRequest)
+	w.Header().Set("Content-Type", content.ContentType)
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Header().Set("X-Transcript-Redacted", strconv.FormatBool(content.Redacted))
+	w.Header().Set("X-Transcript-Version", strconv.Itoa(content.Version))
+	w.WriteHeader(http.StatusOK)
+	w.Write(content.Body)
+}
+
+// CorrectTranscriptSegment fixes the text or speaker name of one segment,
+// creating a new version of the transcript.
+func (h *TranscriptHandler) CorrectTranscriptSegment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomID, err := uuid.Parse(vars["roomId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid room ID")
 		return
 	}
 
-	// Get the transcript file from S3
-	fileReader, err := h.s3TranscriptStorage.GetTranscriptFile(r.Context(), s3KeyPath)
+	messageID, err := uuid.Parse(vars["messageId"])
 	if err != nil {
-		http.Error(w, "Failed to retrieve transcript from storage: "+err.Error(), http.StatusInternalServerError)
+		respondWithError(w, http.StatusBadRequest, "invalid message ID")
 		return
 	}
-	defer fileReader.Close()
 
-	w.Header().Set("Content-Type", "application/json")
+	segmentIndex, err := strconv.Atoi(vars["segmentIndex"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid segment index")
+		return
+	}
+
+	userID, err := getUserIDFromContext(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	var req model.CorrectTranscriptSegmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.Text = strings.TrimSpace(req.Text)
+	if err := utils.ValidateStruct(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	segment, err := h.transcriptService.CorrectSegment(r.Context(), roomID, messageID, segmentIndex, userID, &req)
+	if err != nil {
+		respondWithMessageError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, segment)
+}
+
+// GetTranscriptRevisions lists the corrections of a transcript, each of
+// which created a version that GetTranscript can serve.
+func (h *TranscriptHandler) GetTranscriptRevisions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomID, err := uuid.Parse(vars["roomId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid room ID")
+		return
+	}
+
+	messageID, err := uuid.Parse(vars["messageId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid message ID")
+		return
+	}
+
+	userID, err := getUserIDFromContext(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	revisions, err := h.transcriptService.GetTranscriptRevisions(r.Context(), roomID, messageID, userID)
+	if err != nil {
+		respondWithMessageError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, revisions)
+}
+
+// GetRedactionPolicy returns the room's transcript redaction policy.
+func (h *TranscriptHandler) GetRedactionPolicy(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomID, err := uuid.Parse(vars["roomId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid room ID")
+		return
+	}
+
+	userID, err := getUserIDFromContext(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	policy, err := h.redactionService.GetPolicy(r.Context(), roomID, userID)
+	if err != nil {
+		respondWithMessageError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, policy)
+}
+
+// UpdateRedactionPolicy replaces the room's transcript redaction policy.
+func (h *TranscriptHandler) UpdateRedactionPolicy(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomID, err := uuid.Parse(vars["roomId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid room ID")
+		return
+	}
+
+	userID, err := getUserIDFromContext(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	var req model.UpdateRedactionPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := utils.ValidateStruct(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	policy, err := h.redactionService.UpdatePolicy(r.Context(), roomID, auditActorFromRequest(r, userID), &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRedactionPattern) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondWithMessageError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, policy)
+}
+
+// ExportTranscript renders a transcript message as SRT, WebVTT, Markdown or
+// DOCX, chosen by the format query parameter or the Accept header.
+func (h *TranscriptHandler) ExportTranscript(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomID, err := uuid.Parse(vars["roomId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid room ID")
+		return
+	}
+
+	messageID, err := uuid.Parse(vars["messageId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid message ID")
+		return
+	}
+
+	format, ok, err := negotiateTranscriptFormat(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !ok || format == service.TranscriptFormatJSON {
+		respondWithError(w, http.StatusNotAcceptable, "format must be one of srt, vtt, md or docx")
+		return
+	}
+
+	h.writeTranscriptExport(w, r, roomID, messageID, format)
+}
+
+func (h *TranscriptHandler) writeTranscriptExport(w http.ResponseWriter, r *http.Request, roomID, messageID uuid.UUID, format service.TranscriptFormat) {
+	userID, err := getUserIDFromContext(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	export, err := h.transcriptService.ExportTranscript(r.Context(), roomID, messageID, userID, format)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("room_id", roomID.String()).
+			Str("message_id", messageID.String()).
+			Str("format", string(format)).
+			Msg("Failed to export transcript")
+		respondWithMessageError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", export.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": export.Filename}))
+	w.Header().Set("Content-Length", strconv.Itoa(len(export.Body)))
 	w.WriteHeader(http.StatusOK)
-	if _, err := io.Copy(w, fileReader); err != nil {
-		// Log the error, but response might already be partially sent
-		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to stream transcript content"})
+	w.Write(export.Body)
+}
+
+// negotiateTranscriptFormat picks the export format from the format query
+// parameter, falling back to the Accept header. It reports false when the
+// client did not ask for a particular format.
+func negotiateTranscriptFormat(r *http.Request) (service.TranscriptFormat, bool, error) {
+	if f := r.URL.Query().Get("format"); f != "" {
+		format, ok := service.ParseTranscriptFormat(f)
+		if !ok {
+			return "", false, errors.New("unsupported transcript format: " + f)
+		}
+		return format, true, nil
+	}
+
+	format, ok := service.TranscriptFormatFromAccept(r.Header.Get("Accept"))
+	return format, ok, nil
+}
+
+// GetTranscriptSegments pages through the stored segments of a transcript
+// message. Pass the segment_index of the last segment received as after to
+// load the next page.
+func (h *TranscriptHandler) GetTranscriptSegments(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomID, err := uuid.Parse(vars["roomId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid room ID")
+		return
+	}
+
+	messageID, err := uuid.Parse(vars["messageId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid message ID")
+		return
+	}
+
+	userID, err := getUserIDFromContext(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	limit := 100
+	if l := r.URL.Query().Get("limit"); l != "" {
+		limit, _ = strconv.Atoi(l)
 	}
+
+	after := -1
+	if a := r.URL.Query().Get("after"); a != "" {
+		after, err = strconv.Atoi(a)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid after")
+			return
+		}
+	}
+
+	segments, err := h.transcriptService.GetSegments(r.Context(), roomID, messageID, userID, after, limit)
+	if err != nil {
+		respondWithMessageError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, segments)
+}
+
+func (h *TranscriptHandler) SearchRoomTranscripts(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomID, err := uuid.Parse(vars["roomId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid room ID")
+		return
+	}
+
+	userID, err := getUserIDFromContext(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	query, limit, ok := parseTranscriptSearch(w, r)
+	if !ok {
+		return
+	}
+
+	hits, err := h.transcriptService.SearchTranscripts(r.Context(), roomID, userID, query, limit)
+	if err != nil {
+		respondWithMessageError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, hits)
+}
+
+// SearchTranscripts searches the transcripts of all of the user's rooms.
+func (h *TranscriptHandler) SearchTranscripts(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserIDFromContext(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	query, limit, ok := parseTranscriptSearch(w, r)
+	if !ok {
+		return
+	}
+
+	hits, err := h.transcriptService.SearchMyTranscripts(r.Context(), userID, query, limit)
+	if err != nil {
+		respondWithMessageError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, hits)
+}
+
+func parseTranscriptSearch(w http.ResponseWriter, r *http.Request) (string, int, bool) {
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		respondWithError(w, http.StatusBadRequest, "search query is required")
+		return "", 0, false
+	}
+
+	limit := 20
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			respondWithError(w, http.StatusBadRequest, "invalid limit")
+			return "", 0, false
+		}
+		limit = n
+	}
+
+	return query, limit, true
 }
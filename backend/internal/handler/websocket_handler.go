@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"livekit-consulting/backend/internal/events"
+	"livekit-consulting/backend/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	socketWriteWait  = 10 * time.Second
+	socketPongWait   = 60 * time.Second
+	socketPingPeriod = (socketPongWait * 9) / 10
+	socketReadLimit  = 512
+)
+
+type WebSocketHandler struct {
+	realtimeService *service.RealtimeService
+	upgrader        websocket.Upgrader
+}
+
+func NewWebSocketHandler(realtimeService *service.RealtimeService, allowedOrigin string) *WebSocketHandler {
+	return &WebSocketHandler{
+		realtimeService: realtimeService,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				return origin == "" || allowedOrigin == "*" || origin == allowedOrigin
+			},
+		},
+	}
+}
+
+// ServeRoomSocket streams a room's events to the client over a WebSocket.
+// Passing since_seq replays every message with a higher seq_no before the
+// live stream starts, so reconnecting clients do not miss anything.
+func (h *WebSocketHandler) ServeRoomSocket(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomID, err := uuid.Parse(vars["roomId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid room ID")
+		return
+	}
+
+	userID, err := getUserIDFromContext(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	sinceSeq := -1
+	if s := r.URL.Query().Get("since_seq"); s != "" {
+		sinceSeq, err = strconv.Atoi(s)
+		if err != nil || sinceSeq < 0 {
+			respondWithError(w, http.StatusBadRequest, "invalid since_seq")
+			return
+		}
+	}
+
+	if err := h.realtimeService.Authorize(r.Context(), roomID, userID); err != nil {
+		if errors.Is(err, service.ErrNotRoomMember) {
+			respondWithError(w, http.StatusForbidden, err.Error())
+		} else {
+			respondWithError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	sub := h.realtimeService.Subscribe(roomID)
+	defer sub.Close()
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader has already replied to the client.
+		return
+	}
+	defer conn.Close()
+
+	lastSeq := 0
+	if sinceSeq >= 0 {
+		replay, err := h.realtimeService.Replay(r.Context(), roomID, sinceSeq)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("room_id", roomID.String()).
+				Msg("Failed to replay missed messages")
+			conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, "replay failed"), time.Now().Add(socketWriteWait))
+			return
+		}
+		for _, event := range replay {
+			conn.SetWriteDeadline(time.Now().Add(socketWriteWait))
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+			lastSeq = event.SeqNo
+		}
+	}
+
+	// The read pump only exists to process control frames and notice when
+	// the client goes away; clients do not send anything meaningful.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		conn.SetReadLimit(socketReadLimit)
+		conn.SetReadDeadline(time.Now().Add(socketPongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(socketPongWait))
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	touchPresence(r, h.realtimeService, roomID, userID)
+
+	ticker := time.NewTicker(socketPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(socketWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+			if !stillAuthorized(r, h.realtimeService, roomID, userID) {
+				conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "no longer a room member"), time.Now().Add(socketWriteWait))
+				return
+			}
+			touchPresence(r, h.realtimeService, roomID, userID)
+		case event, ok := <-sub.C:
+			if !ok {
+				// We fell too far behind; the client reconnects with since_seq.
+				conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "subscriber lagging"), time.Now().Add(socketWriteWait))
+				return
+			}
+			if event.Type == events.ParticipantRemoved && !stillAuthorized(r, h.realtimeService, roomID, userID) {
+				conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "no longer a room member"), time.Now().Add(socketWriteWait))
+				return
+			}
+			if event.Type == events.MessageCreated && event.SeqNo <= lastSeq {
+				// Already delivered during replay.
+				continue
+			}
+
+			if allowed, err := h.realtimeService.CanReceive(r.Context(), userID, event); err != nil || !allowed {
+				continue
+			}
+
+			roomEvent, err := h.realtimeService.Resolve(r.Context(), event)
+			if err != nil {
+				log.Error().
+					Err(err).
+					Str("room_id", roomID.String()).
+					Str("event", string(event.Type)).
+					Msg("Failed to resolve room event")
+				continue
+			}
+
+			conn.SetWriteDeadline(time.Now().Add(socketWriteWait))
+			if err := conn.WriteJSON(roomEvent); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// touchPresence refreshes the user's presence in the room. Failures only
+// affect who receives mention emails, so they are logged and ignored.
+func touchPresence(r *http.Request, realtimeService *service.RealtimeService, roomID, userID uuid.UUID) {
+	if err := realtimeService.Touch(r.Context(), roomID, userID); err != nil {
+		log.Warn().
+			Err(err).
+			Str("room_id", roomID.String()).
+			Str("user_id", userID.String()).
+			Msg("Failed to update participant presence")
+	}
+}
+
+// stillAuthorized re-checks that the user may follow the room, so that open
+// connections end once the user is removed from it. Only a lost membership
+// ends the connection; a failed check is logged and the connection kept.
+func stillAuthorized(r *http.Request, realtimeService *service.RealtimeService, roomID, userID uuid.UUID) bool {
+	err := realtimeService.Authorize(r.Context(), roomID, userID)
+	if err == nil {
+		return true
+	}
+	if errors.Is(err, service.ErrNotRoomMember) {
+		return false
+	}
+	log.Warn().
+		Err(err).
+		Str("room_id", roomID.String()).
+		Str("user_id", userID.String()).
+		Msg("Failed to re-check room access")
+	return true
+}
@@ -6,7 +6,8 @@ import (
 	"strings"
 
 	"livekit-consulting/backend/internal/repository"
-	"github.com/dgrijalva/jwt-go"
+	"livekit-consulting/backend/internal/utils"
+
 	"github.com/google/uuid"
 )
 
@@ -25,31 +26,51 @@ func AuthMiddleware(jwtSecret string, userRepo repository.UserRepository) func(h
 				return
 			}
 
-			claims := &jwt.StandardClaims{}
-			token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-				return []byte(jwtSecret), nil
-			})
+			authenticate(w, r, next, tokenString, jwtSecret, userRepo)
+		})
+	}
+}
 
-			if err != nil || !token.Valid {
-				http.Error(w, "Invalid token", http.StatusUnauthorized)
-				return
+// StreamAuthMiddleware performs the same JWT check as AuthMiddleware but also
+// accepts the token in the access_token query parameter, because browsers
+// cannot set an Authorization header on WebSocket or EventSource requests.
+func StreamAuthMiddleware(jwtSecret string, userRepo repository.UserRepository) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tokenString := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if tokenString == "" {
+				tokenString = r.URL.Query().Get("access_token")
 			}
-
-			userID, err := uuid.Parse(claims.Subject)
-			if err != nil {
-				http.Error(w, "Invalid user ID in token", http.StatusUnauthorized)
+			if tokenString == "" {
+				http.Error(w, "Access token required", http.StatusUnauthorized)
 				return
 			}
 
-			user, err := userRepo.GetByID(r.Context(), userID)
-			if err != nil {
-				http.Error(w, "User not found", http.StatusUnauthorized)
-				return
-			}
-
-			ctx := WithUser(r.Context(), user)
-			ctx = context.WithValue(ctx, "userID", claims.Subject)
-			next.ServeHTTP(w, r.WithContext(ctx))
+			authenticate(w, r, next, tokenString, jwtSecret, userRepo)
 		})
 	}
 }
+
+func authenticate(w http.ResponseWriter, r *http.Request, next http.Handler, tokenString, jwtSecret string, userRepo repository.UserRepository) {
+	claims, err := utils.ParseJWT(tokenString, jwtSecret)
+	if err != nil {
+		http.Error(w, "Invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		http.Error(w, "Invalid user ID in token", http.StatusUnauthorized)
+		return
+	}
+
+	user, err := userRepo.GetByID(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "User not found", http.StatusUnauthorized)
+		return
+	}
+
+	ctx := WithUser(r.Context(), user)
+	ctx = context.WithValue(ctx, "userID", claims.Subject)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
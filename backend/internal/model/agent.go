@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultAgentIdentity is the agent that authors agent messages when the
+// agent does not identify itself.
+const DefaultAgentIdentity = "meeting-assistant"
+
+// Agent is a bot principal that can author messages.
+type Agent struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	Identity    string    `json:"identity" db:"identity"`
+	DisplayName string    `json:"display_name" db:"display_name"`
+	AvatarURL   *string   `json:"avatar_url" db:"avatar_url"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audited actions.
+const (
+	AuditActionTranscriptOriginalAccessed = "transcript.original_accessed"
+	AuditActionRedactionPolicyUpdated     = "transcript.redaction_policy_updated"
+	AuditActionTrackMuted                 = "call.track_muted"
+	AuditActionTrackUnmuted               = "call.track_unmuted"
+	AuditActionParticipantRemoved         = "call.participant_removed"
+	AuditActionPermissionsUpdated         = "call.permissions_updated"
+	AuditActionLobbyApproved              = "lobby.approved"
+	AuditActionLobbyDenied                = "lobby.denied"
+)
+
+// AuditLog records a sensitive action taken in a room.
+type AuditLog struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	RoomID     *uuid.UUID `json:"room_id,omitempty" db:"room_id"`
+	ActorID    *uuid.UUID `json:"actor_id,omitempty" db:"actor_id"`
+	ActorName  *string    `json:"actor_name,omitempty" db:"actor_name"` // Joined from users table
+	Action     string     `json:"action" db:"action"`
+	TargetType *string    `json:"target_type,omitempty" db:"target_type"`
+	TargetID   *string    `json:"target_id,omitempty" db:"target_id"`
+	Details    Metadata   `json:"details,omitempty" db:"details"`
+	IPAddress  *string    `json:"ip_address,omitempty" db:"ip_address"`
+	UserAgent  *string    `json:"user_agent,omitempty" db:"user_agent"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
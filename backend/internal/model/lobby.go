@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Lobby request statuses.
+const (
+	LobbyStatusPending  = "pending"
+	LobbyStatusApproved = "approved"
+	LobbyStatusDenied   = "denied"
+)
+
+// LobbyRequest is a guest waiting to be admitted to a room's call.
+type LobbyRequest struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	RoomID        uuid.UUID  `json:"room_id" db:"room_id"`
+	InviteID      uuid.UUID  `json:"-" db:"invite_id"`
+	ParticipantID *uuid.UUID `json:"participant_id,omitempty" db:"participant_id"`
+	Email         string     `json:"email" db:"email"`
+	Name          string     `json:"name" db:"name"`
+	Status        string     `json:"status" db:"status"`
+	DecidedBy     *uuid.UUID `json:"decided_by,omitempty" db:"decided_by"`
+	DecidedAt     *time.Time `json:"decided_at,omitempty" db:"decided_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// JoinRoomResponse answers a guest's join request. LiveKitToken is only set
+// once a host has admitted them.
+type JoinRoomResponse struct {
+	Status       string     `json:"status"`
+	RequestID    *uuid.UUID `json:"request_id,omitempty"`
+	LiveKitToken string     `json:"livekit_token,omitempty"`
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MeetingSession is one call held in a room, from LiveKit starting the room
+// until it closed it.
+type MeetingSession struct {
+	ID                  uuid.UUID  `json:"id" db:"id"`
+	RoomID              uuid.UUID  `json:"room_id" db:"room_id"`
+	RoomSID             string     `json:"room_sid" db:"room_sid"`
+	StartedAt           time.Time  `json:"started_at" db:"started_at"`
+	EndedAt             *time.Time `json:"ended_at,omitempty" db:"ended_at"`
+	TranscriptMessageID *uuid.UUID `json:"transcript_message_id,omitempty" db:"transcript_message_id"`
+	AttendeeCount       int        `json:"attendee_count" db:"attendee_count"`
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at" db:"updated_at"`
+
+	DurationMinutes float64            `json:"duration_minutes" db:"-"`
+	Attendance      []*MeetingAttendee `json:"attendance,omitempty" db:"-"`
+}
+
+// MeetingAttendance is one stay of a participant in a session's call.
+type MeetingAttendance struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	SessionID     uuid.UUID  `json:"session_id" db:"session_id"`
+	ParticipantID *uuid.UUID `json:"participant_id,omitempty" db:"participant_id"`
+	Identity      string     `json:"identity" db:"identity"`
+	Name          string     `json:"name" db:"name"`
+	JoinedAt      time.Time  `json:"joined_at" db:"joined_at"`
+	LeftAt        *time.Time `json:"left_at,omitempty" db:"left_at"`
+}
+
+// MeetingAttendee is everyone's attendance of a session, summed up per
+// LiveKit identity.
+type MeetingAttendee struct {
+	ParticipantID *uuid.UUID           `json:"participant_id,omitempty"`
+	Identity      string               `json:"identity"`
+	Name          string               `json:"name"`
+	Intervals     []AttendanceInterval `json:"intervals"`
+	TotalMinutes  float64              `json:"total_minutes"`
+}
+
+// AttendanceInterval is when an attendee was in the call. LeftAt is unset
+// while they still are.
+type AttendanceInterval struct {
+	JoinedAt time.Time  `json:"joined_at"`
+	LeftAt   *time.Time `json:"left_at,omitempty"`
+}
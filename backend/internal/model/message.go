@@ -19,28 +19,53 @@ const (
 	MessageTypeMeetingTranscript MessageType = "meeting_transcript"
 	// MessageTypeParticipantJoined indicates a participant has joined the room.
 	MessageTypeParticipantJoined MessageType = "participant_joined"
+	// MessageTypeMessagePinned records that a message was pinned to the room.
+	MessageTypeMessagePinned MessageType = "message_pinned"
+	// MessageTypeMessageUnpinned records that a message was unpinned.
+	MessageTypeMessageUnpinned MessageType = "message_unpinned"
+	// MessageTypeMeetingSummary carries a summary of a meeting.
+	MessageTypeMeetingSummary MessageType = "meeting_summary"
+	// MessageTypeActionItems carries the action items agreed in a meeting.
+	MessageTypeActionItems MessageType = "action_items"
+	// MessageTypeAgentJoined indicates the meeting agent joined the call.
+	MessageTypeAgentJoined MessageType = "agent_joined"
+	// MessageTypeAgentLeft indicates the meeting agent left the call.
+	MessageTypeAgentLeft MessageType = "agent_left"
 )
 
 type Message struct {
-	ID          uuid.UUID        `json:"id" db:"id"`
-	RoomID      uuid.UUID        `json:"room_id" db:"room_id"`
-	UserID      *uuid.UUID       `json:"user_id" db:"user_id"`
-	Username    string           `json:"username" db:"username"` // Joined from users table
-	SeqNo       int              `json:"seq_no" db:"seq_no"`
-	Content     string           `json:"content" db:"content"`
-	MessageType MessageType      `json:"message_type" db:"message_type"`
-	Metadata    *MessageMetadata `json:"metadata" db:"metadata"`
-	ExtraData   *ExtraData       `json:"extra_data,omitempty" db:"extra_data"`
-	Edited      bool             `json:"edited" db:"edited"`
-	Attachments []Attachment     `json:"attachments,omitempty"`
-	CreatedAt   time.Time        `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time        `json:"updated_at" db:"updated_at"`
-	DeletedAt   *time.Time       `json:"deleted_at,omitempty" db:"deleted_at"`
+	ID              uuid.UUID        `json:"id" db:"id"`
+	RoomID          uuid.UUID        `json:"room_id" db:"room_id"`
+	UserID          *uuid.UUID       `json:"user_id" db:"user_id"`
+	AgentID         *uuid.UUID       `json:"agent_id,omitempty" db:"agent_id"`
+	Username        string           `json:"username" db:"username"`                             // Joined from users or agents table
+	AuthorAvatarURL *string          `json:"author_avatar_url,omitempty" db:"author_avatar_url"` // Joined from agents table
+	SeqNo           int              `json:"seq_no" db:"seq_no"`
+	Content         string           `json:"content" db:"content"`
+	MessageType     MessageType      `json:"message_type" db:"message_type"`
+	Metadata        *MessageMetadata `json:"metadata" db:"metadata"`
+	ExtraData       *ExtraData       `json:"extra_data,omitempty" db:"extra_data"`
+	Edited          bool             `json:"edited" db:"edited"`
+	ParentMessageID *uuid.UUID       `json:"parent_message_id,omitempty" db:"parent_message_id"`
+	ReplyCount      int              `json:"reply_count" db:"reply_count"`
+	LastReplyAt     *time.Time       `json:"last_reply_at,omitempty" db:"last_reply_at"`
+	PinnedAt        *time.Time       `json:"pinned_at,omitempty" db:"pinned_at"`
+	PinnedBy        *uuid.UUID       `json:"pinned_by,omitempty" db:"pinned_by"`
+	IdempotencyKey  *string          `json:"-" db:"idempotency_key"`
+	Attachments     []Attachment     `json:"attachments,omitempty"`
+	CreatedAt       time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at" db:"updated_at"`
+	DeletedAt       *time.Time       `json:"deleted_at,omitempty" db:"deleted_at"`
 }
 
 // ExtraData holds flexible JSON data for special message types.
 type ExtraData struct {
-	Transcript *TranscriptData `json:"transcript,omitempty"`
+	Transcript  *TranscriptData     `json:"transcript,omitempty"`
+	Pin         *PinData            `json:"pin,omitempty"`
+	Summary     *MeetingSummaryData `json:"summary,omitempty"`
+	ActionItems *ActionItemsData    `json:"action_items,omitempty"`
+	Agent       *AgentData          `json:"agent,omitempty"`
+	Participant *ParticipantData    `json:"participant,omitempty"`
 }
 
 // Scan implements the sql.Scanner interface for ExtraData.
@@ -62,8 +87,8 @@ func (e *ExtraData) Scan(value interface{}) error {
 
 // Value implements the driver.Valuer interface for ExtraData.
 func (e ExtraData) Value() (driver.Value, error) {
-	// If Transcript is nil, we should store a null value in the DB.
-	if e.Transcript == nil {
+	// If no payload is set, we should store a null value in the DB.
+	if e == (ExtraData{}) {
 		return nil, nil
 	}
 	return json.Marshal(e)
@@ -79,6 +104,72 @@ type TranscriptData struct {
 	SessionEnd   time.Time `json:"session_end"`
 }
 
+// PinData identifies the message a pin or unpin system message refers to.
+type PinData struct {
+	MessageID uuid.UUID `json:"message_id"`
+}
+
+// Meeting summary sources.
+const (
+	// SummarySourceAgent marks a summary the meeting agent produced.
+	SummarySourceAgent = "agent"
+	// SummarySourceExtractive marks a summary the backend extracted from the
+	// stored transcript.
+	SummarySourceExtractive = "extractive"
+)
+
+// MeetingSummaryData holds a summary of a meeting. Extractive summaries also
+// reference the transcript they were built from and break down talk time.
+type MeetingSummaryData struct {
+	Summary             string            `json:"summary"`
+	KeyPoints           []string          `json:"key_points,omitempty"`
+	SessionStart        time.Time         `json:"session_start"`
+	SessionEnd          time.Time         `json:"session_end"`
+	Source              string            `json:"source,omitempty"`
+	TranscriptMessageID *uuid.UUID        `json:"transcript_message_id,omitempty"`
+	TalkTime            []SpeakerTalkTime `json:"talk_time,omitempty"`
+}
+
+// SpeakerTalkTime is how long one speaker talked during a meeting.
+type SpeakerTalkTime struct {
+	SpeakerIdentity string  `json:"speaker_identity,omitempty"`
+	SpeakerName     string  `json:"speaker_name"`
+	TalkTimeMs      int64   `json:"talk_time_ms"`
+	Share           float64 `json:"share"` // Fraction of the meeting's total talk time
+	Segments        int     `json:"segments"`
+	Words           int     `json:"words"`
+}
+
+// ActionItemsData holds the action items of a meeting.
+type ActionItemsData struct {
+	Items        []ActionItem `json:"items"`
+	SessionStart time.Time    `json:"session_start"`
+}
+
+// ActionItem is a single follow-up task from a meeting.
+type ActionItem struct {
+	Text     string `json:"text"`
+	Assignee string `json:"assignee,omitempty"`
+	DueDate  string `json:"due_date,omitempty"`
+	StartMs  *int64 `json:"start_ms,omitempty"` // Offset into the transcript, for detected items
+}
+
+// AgentData identifies the agent an agent_joined or agent_left message is
+// about.
+type AgentData struct {
+	Identity string `json:"identity"`
+	Name     string `json:"name"`
+}
+
+// ParticipantData identifies the call participant a participant_joined
+// message is about. ParticipantID is unset for identities that are not room
+// participants.
+type ParticipantData struct {
+	ParticipantID *uuid.UUID `json:"participant_id,omitempty"`
+	Identity      string     `json:"identity"`
+	Name          string     `json:"name"`
+}
+
 // S3Keys holds the S3 object keys for the transcript files.
 type S3Keys struct {
 	JSON string `json:"json"`
@@ -91,6 +182,25 @@ type HTTPSUrls struct {
 	Text string `json:"text_https_url"`
 }
 
+// Revision actions recorded in a message's history.
+const (
+	RevisionActionEdit   = "edit"
+	RevisionActionDelete = "delete"
+)
+
+// MessageRevision records the content of a message before it was edited or
+// deleted.
+type MessageRevision struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	MessageID       uuid.UUID  `json:"message_id" db:"message_id"`
+	EditorID        *uuid.UUID `json:"editor_id" db:"editor_id"`
+	EditorName      *string    `json:"editor_name" db:"editor_name"` // Joined from users table
+	Action          string     `json:"action" db:"action"`
+	PreviousContent string     `json:"previous_content" db:"previous_content"`
+	NewContent      *string    `json:"new_content,omitempty" db:"new_content"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
+
 type MessageMetadata struct {
 	Reactions []Reaction `json:"reactions,omitempty"`
 	Mentions  []string   `json:"mentions,omitempty"`
@@ -123,8 +233,28 @@ type Reaction struct {
 }
 
 type CreateMessageRequest struct {
-	Content       string      `json:"content" validate:"required,max=5000"`
-	AttachmentIDs []uuid.UUID `json:"attachment_ids,omitempty"`
+	Content         string      `json:"content" validate:"required,max=5000"`
+	AttachmentIDs   []uuid.UUID `json:"attachment_ids,omitempty"`
+	ParentMessageID *uuid.UUID  `json:"parent_message_id,omitempty"`
+}
+
+// ThreadResponse is a thread's root message with a page of its replies.
+type ThreadResponse struct {
+	Root    *Message   `json:"root"`
+	Replies []*Message `json:"replies"`
+}
+
+type ReactionRequest struct {
+	Emoji string `json:"emoji" validate:"required,max=64"`
+}
+
+// MessageCursor selects a page of a room's timeline. At most one field is
+// set; without any the newest messages are returned.
+type MessageCursor struct {
+	Before    *uuid.UUID // deprecated: prefer BeforeSeq
+	BeforeSeq *int
+	AfterSeq  *int
+	AroundSeq *int
 }
 
 type UpdateMessageRequest struct {
@@ -133,4 +263,4 @@ type UpdateMessageRequest struct {
 
 type UpdateLastReadRequest struct {
 	LastReadSequenceNumber int `json:"last_read_sequence_number"`
-}
\ No newline at end of file
+}
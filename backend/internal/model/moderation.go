@@ -0,0 +1,13 @@
+package model
+
+// MuteTrackRequest mutes or unmutes a track published in a room's call.
+type MuteTrackRequest struct {
+	Muted bool `json:"muted"`
+}
+
+// UpdateCallPermissionsRequest changes what a participant may do in the
+// room's call. Unset fields keep their current value.
+type UpdateCallPermissionsRequest struct {
+	CanPublish   *bool `json:"can_publish"`
+	CanSubscribe *bool `json:"can_subscribe"`
+}
@@ -6,6 +6,15 @@ import (
 	"github.com/google/uuid"
 )
 
+// Participant roles within a room.
+const (
+	RoleOwner       = "owner"
+	RoleModerator   = "moderator"
+	RoleParticipant = "participant"
+	// RoleViewer can watch and listen to the call but not publish to it.
+	RoleViewer = "viewer"
+)
+
 type RoomParticipant struct {
 	ID              uuid.UUID  `json:"id" db:"id"`
 	RoomID          uuid.UUID  `json:"room_id" db:"room_id"`
@@ -19,12 +28,26 @@ type RoomParticipant struct {
 	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
 	LastViewedAt    *time.Time `json:"last_viewed_at" db:"last_viewed_at"`
 	LastReadSeqNo   int        `json:"last_read_seq_no" db:"last_read_seq_no"`
+	LastSeenAt      *time.Time `json:"last_seen_at" db:"last_seen_at"`
 	IsActive        bool       `json:"is_active" db:"is_active"`
 }
 
+// CanModerate reports whether the participant may moderate the room.
+func (p *RoomParticipant) CanModerate() bool {
+	return p.IsActive && (p.Role == RoleOwner || p.Role == RoleModerator)
+}
+
+// IsOnline reports whether the participant has had a realtime connection to
+// the room open within the given window.
+func (p *RoomParticipant) IsOnline(window time.Duration) bool {
+	return p.LastSeenAt != nil && time.Since(*p.LastSeenAt) < window
+}
+
 type AddParticipantRequest struct {
 	Email string `json:"email" validate:"required,email"`
 	Name  string `json:"name" validate:"required,min=2"`
+	// Role defaults to participant.
+	Role string `json:"role" validate:"omitempty,oneof=moderator participant viewer"`
 }
 
 type ParticipantInviteResponse struct {
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Built-in redaction categories.
+const (
+	RedactionEmail = "email"
+	RedactionPhone = "phone"
+	RedactionCard  = "card"
+	RedactionSSN   = "ssn"
+	RedactionIP    = "ip"
+)
+
+// TranscriptRedactionPolicy controls which personal data is masked in a
+// room's transcripts before they are shown.
+type TranscriptRedactionPolicy struct {
+	RoomID         uuid.UUID         `json:"room_id" db:"room_id"`
+	Enabled        bool              `json:"enabled" db:"enabled"`
+	Categories     pq.StringArray    `json:"categories" db:"categories"`
+	CustomPatterns RedactionPatterns `json:"custom_patterns" db:"custom_patterns"`
+	UpdatedBy      *uuid.UUID        `json:"updated_by,omitempty" db:"updated_by"`
+	CreatedAt      time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// RedactionPattern is a room-specific regular expression whose matches are
+// replaced by the pattern's name.
+type RedactionPattern struct {
+	Name    string `json:"name" validate:"required,max=50"`
+	Pattern string `json:"pattern" validate:"required,max=500"`
+}
+
+type RedactionPatterns []RedactionPattern
+
+// Scan implements the sql.Scanner interface for RedactionPatterns.
+func (p *RedactionPatterns) Scan(value interface{}) error {
+	if value == nil {
+		*p = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("Scan source was not []byte for RedactionPatterns")
+	}
+	return json.Unmarshal(bytes, p)
+}
+
+// Value implements the driver.Valuer interface for RedactionPatterns.
+func (p RedactionPatterns) Value() (driver.Value, error) {
+	if p == nil {
+		return "[]", nil
+	}
+	return json.Marshal(p)
+}
+
+type UpdateRedactionPolicyRequest struct {
+	Enabled        bool               `json:"enabled"`
+	Categories     []string           `json:"categories" validate:"dive,oneof=email phone card ssn ip"`
+	CustomPatterns []RedactionPattern `json:"custom_patterns" validate:"max=20,dive"`
+}
@@ -47,11 +47,21 @@ type Room struct {
 	LastMessageSeq  int       `json:"last_message_seq" db:"last_message_seq"`
 	LastMessageAt   *time.Time `json:"last_message_at" db:"last_message_at"`
 	IsActive        bool      `json:"is_active" db:"is_active"`
+	// TokenTTLSeconds is how long LiveKit tokens for the room stay valid;
+	// unset uses the server default.
+	TokenTTLSeconds *int `json:"token_ttl_seconds" db:"token_ttl_seconds"`
 }
 
 type CreateRoomRequest struct {
-    RoomName    string  `json:"room_name" validate:"required,min=3,max=100"`
-    Description *string `json:"description"`
+    RoomName        string  `json:"room_name" validate:"required,min=3,max=100"`
+    Description     *string `json:"description"`
+    TokenTTLSeconds *int    `json:"token_ttl_seconds" validate:"omitempty,min=300,max=604800"`
+}
+
+// UpdateRoomSettingsRequest changes a room's settings. An unset
+// TokenTTLSeconds restores the server default.
+type UpdateRoomSettingsRequest struct {
+    TokenTTLSeconds *int `json:"token_ttl_seconds" validate:"omitempty,min=300,max=604800"`
 }
 
 type RoomResponse struct {
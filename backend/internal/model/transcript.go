@@ -0,0 +1,128 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TranscriptSegment is a single utterance of a meeting transcript. Offsets are
+// in milliseconds from the start of the session.
+type TranscriptSegment struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	RoomID          uuid.UUID  `json:"room_id" db:"room_id"`
+	MessageID       uuid.UUID  `json:"message_id" db:"message_id"`
+	SegmentIndex    int        `json:"segment_index" db:"segment_index"`
+	SpeakerIdentity string     `json:"speaker_identity" db:"speaker_identity"`
+	SpeakerName     string     `json:"speaker_name" db:"speaker_name"`
+	Role            string     `json:"role" db:"role"`
+	StartMs         int64      `json:"start_ms" db:"start_ms"`
+	EndMs           int64      `json:"end_ms" db:"end_ms"`
+	Text            string     `json:"text" db:"text"`
+	Version         int        `json:"version" db:"version"` // Transcript version of the last correction, 0 if uncorrected
+	EditedBy        *uuid.UUID `json:"edited_by,omitempty" db:"edited_by"`
+	EditedAt        *time.Time `json:"edited_at,omitempty" db:"edited_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
+
+// TranscriptSegmentRevision records one correction of a transcript segment.
+// Each correction creates the next version of the transcript.
+type TranscriptSegmentRevision struct {
+	ID                  uuid.UUID  `json:"id" db:"id"`
+	SegmentID           uuid.UUID  `json:"segment_id" db:"segment_id"`
+	MessageID           uuid.UUID  `json:"message_id" db:"message_id"`
+	SegmentIndex        int        `json:"segment_index" db:"segment_index"`
+	Version             int        `json:"version" db:"version"`
+	EditorID            *uuid.UUID `json:"editor_id" db:"editor_id"`
+	EditorName          *string    `json:"editor_name" db:"editor_name"` // Joined from users table
+	PreviousText        string     `json:"previous_text" db:"previous_text"`
+	NewText             string     `json:"new_text" db:"new_text"`
+	PreviousSpeakerName string     `json:"previous_speaker_name" db:"previous_speaker_name"`
+	NewSpeakerName      string     `json:"new_speaker_name" db:"new_speaker_name"`
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
+}
+
+type CorrectTranscriptSegmentRequest struct {
+	Text        string  `json:"text" validate:"required,max=10000"`
+	SpeakerName *string `json:"speaker_name" validate:"omitempty,max=255"`
+}
+
+// TranscriptSearchHit is a transcript segment matching a search, with the
+// matching words wrapped in <mark> tags in Snippet.
+type TranscriptSearchHit struct {
+	TranscriptSegment
+	RoomName     string    `json:"room_name" db:"room_name"`
+	SessionStart time.Time `json:"session_start" db:"session_start"`
+	Snippet      string    `json:"snippet" db:"snippet"`
+}
+
+// TranscriptFile is the JSON transcript the agent uploads at the end of a
+// session.
+type TranscriptFile struct {
+	RoomName     string           `json:"room_name"`
+	SessionStart TranscriptTime   `json:"session_start"`
+	SessionEnd   TranscriptTime   `json:"session_end"`
+	Items        []TranscriptItem `json:"items"`
+}
+
+// TranscriptItem is one entry of the agent's transcript file.
+type TranscriptItem struct {
+	Timestamp       TranscriptTime          `json:"timestamp"`
+	Role            string                  `json:"role"`
+	Interrupted     bool                    `json:"interrupted"`
+	Content         []TranscriptItemContent `json:"content"`
+	SpeakerIdentity string                  `json:"speaker_identity"`
+	SpeakerName     string                  `json:"speaker_name"`
+}
+
+type TranscriptItemContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// TranscriptTime accepts the timestamp formats the agent has written over
+// time: RFC 3339, ISO 8601 without a zone (taken as UTC) and Unix seconds.
+type TranscriptTime struct {
+	time.Time
+}
+
+var transcriptTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+}
+
+func (t *TranscriptTime) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err == nil {
+		t.Time = time.Unix(0, int64(seconds*float64(time.Second))).UTC()
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		return nil
+	}
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		t.Time = time.Unix(0, int64(seconds*float64(time.Second))).UTC()
+		return nil
+	}
+	for _, layout := range transcriptTimeLayouts {
+		if parsed, err := time.Parse(layout, s); err == nil {
+			t.Time = parsed.UTC()
+			return nil
+		}
+	}
+	return errors.New("unrecognised transcript timestamp: " + s)
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"livekit-consulting/backend/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type AgentRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Agent, error)
+	GetByIdentity(ctx context.Context, identity string) (*model.Agent, error)
+	List(ctx context.Context) ([]*model.Agent, error)
+	Upsert(ctx context.Context, identity, displayName string) (*model.Agent, error)
+}
+
+type agentRepository struct {
+	db *sqlx.DB
+}
+
+func NewAgentRepository(db *sqlx.DB) AgentRepository {
+	return &agentRepository{db: db}
+}
+
+func (r *agentRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Agent, error) {
+	var agent model.Agent
+	err := r.db.GetContext(ctx, &agent, "SELECT * FROM agents WHERE id = $1", id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &agent, nil
+}
+
+func (r *agentRepository) GetByIdentity(ctx context.Context, identity string) (*model.Agent, error) {
+	var agent model.Agent
+	err := r.db.GetContext(ctx, &agent, "SELECT * FROM agents WHERE identity = $1", identity)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &agent, nil
+}
+
+func (r *agentRepository) List(ctx context.Context) ([]*model.Agent, error) {
+	var agents []*model.Agent
+	err := r.db.SelectContext(ctx, &agents, "SELECT * FROM agents ORDER BY display_name")
+	return agents, err
+}
+
+// Upsert registers the agent with the given identity, or renames it when it
+// already exists. An empty displayName keeps the current name.
+func (r *agentRepository) Upsert(ctx context.Context, identity, displayName string) (*model.Agent, error) {
+	rename := displayName != ""
+	if !rename {
+		displayName = identity
+	}
+	query := `
+		INSERT INTO agents (identity, display_name)
+		VALUES ($1, $2)
+		ON CONFLICT (identity) DO UPDATE
+		SET display_name = CASE WHEN $3 THEN EXCLUDED.display_name ELSE agents.display_name END,
+		    updated_at = CASE WHEN $3 AND agents.display_name <> EXCLUDED.display_name THEN NOW() ELSE agents.updated_at END
+		RETURNING *
+	`
+	var agent model.Agent
+	err := r.db.GetContext(ctx, &agent, query, identity, displayName, rename)
+	if err != nil {
+		return nil, err
+	}
+	return &agent, nil
+}
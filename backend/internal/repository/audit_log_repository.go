@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"livekit-consulting/backend/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type AuditLogRepository interface {
+	Create(ctx context.Context, entry *model.AuditLog) error
+	GetByRoomID(ctx context.Context, roomID uuid.UUID, limit int, before *time.Time) ([]*model.AuditLog, error)
+}
+
+type auditLogRepository struct {
+	db *sqlx.DB
+}
+
+func NewAuditLogRepository(db *sqlx.DB) AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+func (r *auditLogRepository) Create(ctx context.Context, entry *model.AuditLog) error {
+	query := `
+		INSERT INTO audit_logs (room_id, actor_id, action, target_type, target_id, details, ip_address, user_agent)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+	return r.db.QueryRowContext(ctx, query,
+		entry.RoomID, entry.ActorID, entry.Action, entry.TargetType, entry.TargetID,
+		entry.Details, entry.IPAddress, entry.UserAgent,
+	).Scan(&entry.ID, &entry.CreatedAt)
+}
+
+// GetByRoomID returns the room's audit log, newest first, starting before the
+// given time when set.
+func (r *auditLogRepository) GetByRoomID(ctx context.Context, roomID uuid.UUID, limit int, before *time.Time) ([]*model.AuditLog, error) {
+	query := `
+		SELECT a.id, a.room_id, a.actor_id, u.name as actor_name, a.action, a.target_type, a.target_id,
+		       a.details, a.ip_address, a.user_agent, a.created_at
+		FROM audit_logs a
+		LEFT JOIN users u ON a.actor_id = u.id
+		WHERE a.room_id = $1 AND ($3::timestamptz IS NULL OR a.created_at < $3)
+		ORDER BY a.created_at DESC
+		LIMIT $2
+	`
+	var entries []*model.AuditLog
+	err := r.db.SelectContext(ctx, &entries, query, roomID, limit, before)
+	return entries, err
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+
+	"livekit-consulting/backend/internal/events"
+	"livekit-consulting/backend/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+// publishEvent announces a committed change on the event bus. Delivery is
+// best effort: the change itself has already been stored, and clients that
+// miss an event catch up from the database.
+func publishEvent(ctx context.Context, publisher events.Publisher, event events.Event) {
+	if err := publisher.Publish(ctx, event); err != nil {
+		log.Error().
+			Err(err).
+			Str("room_id", event.RoomID.String()).
+			Str("event", string(event.Type)).
+			Msg("Failed to publish room event")
+	}
+}
+
+// participantEventData is the payload of participant events. Like every
+// event it only identifies the participant; subscribers load the rest.
+func participantEventData(participant *model.RoomParticipant) map[string]interface{} {
+	return map[string]interface{}{
+		"participant_id": participant.ID,
+		"user_id":        participant.UserID,
+		"name":           participant.Name,
+	}
+}
+
+// roomEventData is the payload of room events.
+func roomEventData(room *model.Room) map[string]interface{} {
+	return map[string]interface{}{
+		"room_name": room.RoomName,
+	}
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"livekit-consulting/backend/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type LobbyRequestRepository interface {
+	Create(ctx context.Context, request *model.LobbyRequest) (bool, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*model.LobbyRequest, error)
+	GetByInviteID(ctx context.Context, inviteID uuid.UUID) (*model.LobbyRequest, error)
+	GetPendingByRoomID(ctx context.Context, roomID uuid.UUID) ([]*model.LobbyRequest, error)
+	Decide(ctx context.Context, id uuid.UUID, status string, decidedBy uuid.UUID) (*model.LobbyRequest, error)
+}
+
+type lobbyRequestRepository struct {
+	db *sqlx.DB
+}
+
+func NewLobbyRequestRepository(db *sqlx.DB) LobbyRequestRepository {
+	return &lobbyRequestRepository{db: db}
+}
+
+// Create puts a guest in the lobby. It reports false, leaving request
+// untouched, when their invite already has a lobby request.
+func (r *lobbyRequestRepository) Create(ctx context.Context, request *model.LobbyRequest) (bool, error) {
+	query := `
+		INSERT INTO lobby_requests (room_id, invite_id, participant_id, email, name)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (invite_id) DO NOTHING
+		RETURNING id, status, created_at, updated_at
+	`
+	err := r.db.QueryRowxContext(ctx, query, request.RoomID, request.InviteID, request.ParticipantID, request.Email, request.Name).Scan(
+		&request.ID, &request.Status, &request.CreatedAt, &request.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *lobbyRequestRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.LobbyRequest, error) {
+	var request model.LobbyRequest
+	query := `SELECT * FROM lobby_requests WHERE id = $1`
+	err := r.db.GetContext(ctx, &request, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (r *lobbyRequestRepository) GetByInviteID(ctx context.Context, inviteID uuid.UUID) (*model.LobbyRequest, error) {
+	var request model.LobbyRequest
+	query := `SELECT * FROM lobby_requests WHERE invite_id = $1`
+	err := r.db.GetContext(ctx, &request, query, inviteID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+// GetPendingByRoomID returns the guests waiting in the room's lobby, longest
+// waiting first.
+func (r *lobbyRequestRepository) GetPendingByRoomID(ctx context.Context, roomID uuid.UUID) ([]*model.LobbyRequest, error) {
+	query := `
+		SELECT * FROM lobby_requests
+		WHERE room_id = $1 AND status = 'pending'
+		ORDER BY created_at ASC
+	`
+	var requests []*model.LobbyRequest
+	err := r.db.SelectContext(ctx, &requests, query, roomID)
+	return requests, err
+}
+
+// Decide approves or denies a pending request. It returns nil when the
+// request was no longer pending, so two hosts cannot both decide it.
+func (r *lobbyRequestRepository) Decide(ctx context.Context, id uuid.UUID, status string, decidedBy uuid.UUID) (*model.LobbyRequest, error) {
+	var request model.LobbyRequest
+	query := `
+		UPDATE lobby_requests
+		SET status = $2, decided_by = $3, decided_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING *
+	`
+	err := r.db.GetContext(ctx, &request, query, id, status, decidedBy)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &request, nil
+}
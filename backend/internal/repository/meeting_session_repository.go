@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"livekit-consulting/backend/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type MeetingSessionRepository interface {
+	Start(ctx context.Context, session *model.MeetingSession) error
+	End(ctx context.Context, roomSID string, endedAt time.Time) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.MeetingSession, error)
+	GetBySID(ctx context.Context, roomSID string) (*model.MeetingSession, error)
+	GetByRoomID(ctx context.Context, roomID uuid.UUID, limit int, before *time.Time) ([]*model.MeetingSession, error)
+	LinkTranscript(ctx context.Context, roomID, messageID uuid.UUID, at time.Time) (*model.MeetingSession, error)
+	RecordJoin(ctx context.Context, attendance *model.MeetingAttendance) error
+	RecordLeave(ctx context.Context, sessionID uuid.UUID, identity string, leftAt time.Time) error
+	GetAttendance(ctx context.Context, sessionID uuid.UUID) ([]*model.MeetingAttendance, error)
+}
+
+type meetingSessionRepository struct {
+	db *sqlx.DB
+}
+
+func NewMeetingSessionRepository(db *sqlx.DB) MeetingSessionRepository {
+	return &meetingSessionRepository{db: db}
+}
+
+const meetingSessionColumns = `
+	s.id, s.room_id, s.room_sid, s.started_at, s.ended_at, s.transcript_message_id, s.created_at, s.updated_at,
+	(SELECT COUNT(DISTINCT a.identity) FROM meeting_attendance a WHERE a.session_id = s.id) as attendee_count
+`
+
+// Start records the session for a LiveKit room SID, or loads it when it was
+// already recorded, so repeated and out-of-order webhooks share one session.
+func (r *meetingSessionRepository) Start(ctx context.Context, session *model.MeetingSession) error {
+	query := `
+		INSERT INTO meeting_sessions (room_id, room_sid, started_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (room_sid) DO UPDATE
+		SET started_at = LEAST(meeting_sessions.started_at, EXCLUDED.started_at)
+		RETURNING id, started_at, ended_at, transcript_message_id, created_at, updated_at
+	`
+	return r.db.QueryRowxContext(ctx, query, session.RoomID, session.RoomSID, session.StartedAt).Scan(
+		&session.ID, &session.StartedAt, &session.EndedAt, &session.TranscriptMessageID, &session.CreatedAt, &session.UpdatedAt,
+	)
+}
+
+// End marks the session as ended and closes the attendance of everyone
+// still in the call.
+func (r *meetingSessionRepository) End(ctx context.Context, roomSID string, endedAt time.Time) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var sessionID uuid.UUID
+	query := `
+		UPDATE meeting_sessions
+		SET ended_at = COALESCE(ended_at, $2), updated_at = NOW()
+		WHERE room_sid = $1
+		RETURNING id, ended_at
+	`
+	if err := tx.QueryRowxContext(ctx, query, roomSID, endedAt).Scan(&sessionID, &endedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+
+	query = `UPDATE meeting_attendance SET left_at = $2 WHERE session_id = $1 AND left_at IS NULL`
+	if _, err := tx.ExecContext(ctx, query, sessionID, endedAt); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *meetingSessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.MeetingSession, error) {
+	var session model.MeetingSession
+	query := `SELECT ` + meetingSessionColumns + ` FROM meeting_sessions s WHERE s.id = $1`
+	err := r.db.GetContext(ctx, &session, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *meetingSessionRepository) GetBySID(ctx context.Context, roomSID string) (*model.MeetingSession, error) {
+	var session model.MeetingSession
+	query := `SELECT ` + meetingSessionColumns + ` FROM meeting_sessions s WHERE s.room_sid = $1`
+	err := r.db.GetContext(ctx, &session, query, roomSID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetByRoomID returns the room's sessions, latest first, starting before the
+// given time when set.
+func (r *meetingSessionRepository) GetByRoomID(ctx context.Context, roomID uuid.UUID, limit int, before *time.Time) ([]*model.MeetingSession, error) {
+	query := `
+		SELECT ` + meetingSessionColumns + `
+		FROM meeting_sessions s
+		WHERE s.room_id = $1 AND ($3::timestamptz IS NULL OR s.started_at < $3)
+		ORDER BY s.started_at DESC
+		LIMIT $2
+	`
+	var sessions []*model.MeetingSession
+	err := r.db.SelectContext(ctx, &sessions, query, roomID, limit, before)
+	return sessions, err
+}
+
+// LinkTranscript links a transcript message to the latest session of the
+// room that had started by the given time and has no transcript yet. It
+// returns the linked session, or nil when there was none.
+func (r *meetingSessionRepository) LinkTranscript(ctx context.Context, roomID, messageID uuid.UUID, at time.Time) (*model.MeetingSession, error) {
+	query := `
+		UPDATE meeting_sessions
+		SET transcript_message_id = $2, updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM meeting_sessions
+			WHERE room_id = $1 AND started_at <= $3
+			ORDER BY started_at DESC
+			LIMIT 1
+		) AND transcript_message_id IS NULL
+		RETURNING id
+	`
+	var sessionID uuid.UUID
+	err := r.db.QueryRowxContext(ctx, query, roomID, messageID, at).Scan(&sessionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return r.GetByID(ctx, sessionID)
+}
+
+// RecordJoin records that a participant joined the call. LiveKit resends
+// webhooks, so a join already recorded is ignored.
+func (r *meetingSessionRepository) RecordJoin(ctx context.Context, attendance *model.MeetingAttendance) error {
+	query := `
+		INSERT INTO meeting_attendance (session_id, participant_id, identity, name, joined_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (session_id, identity, joined_at) DO NOTHING
+	`
+	_, err := r.db.ExecContext(ctx, query, attendance.SessionID, attendance.ParticipantID, attendance.Identity, attendance.Name, attendance.JoinedAt)
+	return err
+}
+
+// RecordLeave closes the participant's latest open stay in the call.
+func (r *meetingSessionRepository) RecordLeave(ctx context.Context, sessionID uuid.UUID, identity string, leftAt time.Time) error {
+	query := `
+		UPDATE meeting_attendance
+		SET left_at = GREATEST(joined_at, $3)
+		WHERE id = (
+			SELECT id FROM meeting_attendance
+			WHERE session_id = $1 AND identity = $2 AND left_at IS NULL
+			ORDER BY joined_at DESC
+			LIMIT 1
+		)
+	`
+	_, err := r.db.ExecContext(ctx, query, sessionID, identity, leftAt)
+	return err
+}
+
+func (r *meetingSessionRepository) GetAttendance(ctx context.Context, sessionID uuid.UUID) ([]*model.MeetingAttendance, error) {
+	query := `
+		SELECT id, session_id, participant_id, identity, name, joined_at, left_at
+		FROM meeting_attendance
+		WHERE session_id = $1
+		ORDER BY joined_at ASC
+	`
+	var attendance []*model.MeetingAttendance
+	err := r.db.SelectContext(ctx, &attendance, query, sessionID)
+	return attendance, err
+}
@@ -3,33 +3,67 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"time"
 
+	"livekit-consulting/backend/internal/events"
 	"livekit-consulting/backend/internal/model"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+// ErrDuplicateIdempotencyKey is returned by Create when a message with the
+// same idempotency key already exists.
+var ErrDuplicateIdempotencyKey = errors.New("message with this idempotency key already exists")
+
 type MessageRepository interface {
 	Create(ctx context.Context, message *model.Message) (*model.Room, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*model.Message, error)
+	GetByIdempotencyKey(ctx context.Context, key string) (*model.Message, error)
 	GetByRoomID(ctx context.Context, roomID uuid.UUID, limit int, before *uuid.UUID) ([]*model.Message, error)
-	Update(ctx context.Context, id uuid.UUID, content string) error
-	Delete(ctx context.Context, id uuid.UUID) error
+	GetByRoomIDBeforeSeq(ctx context.Context, roomID uuid.UUID, beforeSeq int, limit int) ([]*model.Message, error)
+	GetByRoomIDAfterSeq(ctx context.Context, roomID uuid.UUID, afterSeq int, limit int, includeReplies bool) ([]*model.Message, error)
+	GetThread(ctx context.Context, rootID uuid.UUID, limit int, before *uuid.UUID) ([]*model.Message, error)
+	Update(ctx context.Context, id, editorID uuid.UUID, content string) error
+	Delete(ctx context.Context, id, editorID uuid.UUID) error
+	GetRevisions(ctx context.Context, roomID, messageID uuid.UUID) ([]*model.MessageRevision, error)
 	Search(ctx context.Context, roomID uuid.UUID, searchTerm string, limit int) ([]*model.Message, error)
+	GetMentions(ctx context.Context, userID uuid.UUID, limit int, before *uuid.UUID) ([]*model.Message, error)
 	GetMessageWithAttachments(ctx context.Context, id uuid.UUID) (*model.Message, error)
 	UpdateMetadata(ctx context.Context, id uuid.UUID, metadata *model.MessageMetadata) error
+	Pin(ctx context.Context, id, pinnedBy uuid.UUID) (bool, error)
+	Unpin(ctx context.Context, id uuid.UUID) (bool, error)
+	GetPinned(ctx context.Context, roomID uuid.UUID) ([]*model.Message, error)
 }
 
+// messageColumns is the select list shared by the message queries; it
+// expects the tables of messageJoins. The author name comes from the user or
+// the agent that posted the message.
+const messageColumns = `
+        m.id, m.room_id, m.user_id, m.agent_id, COALESCE(u.name, a.display_name, '') as username,
+        a.avatar_url as author_avatar_url, m.seq_no, m.content, m.message_type, m.extra_data, m.metadata,
+        m.edited, m.parent_message_id, m.reply_count, m.last_reply_at, m.pinned_at, m.pinned_by,
+        m.created_at, m.updated_at`
+
+// messageJoins joins the authors of messages aliased as m.
+const messageJoins = `
+        LEFT JOIN users u ON m.user_id = u.id
+        LEFT JOIN agents a ON m.agent_id = a.id`
+
 type messageRepository struct {
-	db *sqlx.DB
+	db        *sqlx.DB
+	publisher events.Publisher
 }
 
-func NewMessageRepository(db *sqlx.DB) MessageRepository {
-	return &messageRepository{db: db}
+func NewMessageRepository(db *sqlx.DB, publisher events.Publisher) MessageRepository {
+	return &messageRepository{db: db, publisher: publisher}
 }
 
+// Create stores the message with the room's next sequence number and links
+// the attachments listed in message.Attachments (only their IDs are used).
+// Replies also bump the reply_count/last_reply_at rollup of their root.
 func (r *messageRepository) Create(ctx context.Context, message *model.Message) (*model.Room, error) {
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
@@ -46,8 +80,8 @@ func (r *messageRepository) Create(ctx context.Context, message *model.Message)
 	message.SeqNo = room.LastMessageSeq
 
 	query := `
-        INSERT INTO messages (id, room_id, user_id, seq_no, content, message_type, metadata, extra_data, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+        INSERT INTO messages (id, room_id, user_id, agent_id, seq_no, content, message_type, metadata, extra_data, parent_message_id, idempotency_key, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
         RETURNING id, created_at
     `
 	message.ID = uuid.New()
@@ -64,28 +98,69 @@ func (r *messageRepository) Create(ctx context.Context, message *model.Message)
 		message.ID,
 		message.RoomID,
 		message.UserID,
+		message.AgentID,
 		message.SeqNo,
 		message.Content,
 		message.MessageType,
 		message.Metadata,
 		message.ExtraData,
+		message.ParentMessageID,
+		message.IdempotencyKey,
 		message.CreatedAt,
 		message.UpdatedAt,
 	).Scan(&message.ID, &message.CreatedAt)
 
 	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "idx_messages_idempotency_key" {
+			return nil, ErrDuplicateIdempotencyKey
+		}
+		return nil, err
+	}
+
+	var root *model.Message
+	if message.ParentMessageID != nil {
+		root = &model.Message{}
+		rollup := `
+            UPDATE messages
+            SET reply_count = reply_count + 1, last_reply_at = $1
+            WHERE id = $2
+            RETURNING id, room_id, seq_no
+        `
+		err = tx.GetContext(ctx, root, rollup, message.CreatedAt, *message.ParentMessageID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(message.Attachments) > 0 {
+		attachmentIDs := make([]uuid.UUID, len(message.Attachments))
+		for i, attachment := range message.Attachments {
+			attachmentIDs[i] = attachment.ID
+		}
+		_, err = tx.ExecContext(ctx, "UPDATE attachments SET message_id = $1 WHERE id = ANY($2) AND message_id IS NULL", message.ID, pq.Array(attachmentIDs))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
 		return nil, err
 	}
 
-	return &room, tx.Commit()
+	publishEvent(ctx, r.publisher, events.NewMessageEvent(events.MessageCreated, message.RoomID, message.ID, message.SeqNo))
+	if root != nil {
+		publishEvent(ctx, r.publisher, events.NewMessageEvent(events.MessageUpdated, root.RoomID, root.ID, root.SeqNo))
+	}
+
+	return &room, nil
 }
 
 func (r *messageRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Message, error) {
 	query := `
-        SELECT m.id, m.room_id, m.user_id, u.name as username, m.seq_no, m.content, m.message_type, m.extra_data, m.metadata,
-               m.edited, m.created_at, m.updated_at, m.deleted_at
+        SELECT ` + messageColumns + `, m.deleted_at
         FROM messages m
-        LEFT JOIN users u ON m.user_id = u.id
+        ` + messageJoins + `
         WHERE m.id = $1 AND m.deleted_at IS NULL
     `
 
@@ -98,30 +173,47 @@ func (r *messageRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.M
 	return &message, nil
 }
 
+// GetByIdempotencyKey returns the message created with the given key, even
+// if it has since been deleted, or sql.ErrNoRows.
+func (r *messageRepository) GetByIdempotencyKey(ctx context.Context, key string) (*model.Message, error) {
+	query := `
+        SELECT ` + messageColumns + `, m.deleted_at
+        FROM messages m
+        ` + messageJoins + `
+        WHERE m.idempotency_key = $1
+    `
+
+	var message model.Message
+	err := r.db.GetContext(ctx, &message, query, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &message, nil
+}
+
 func (r *messageRepository) GetByRoomID(ctx context.Context, roomID uuid.UUID, limit int, before *uuid.UUID) ([]*model.Message, error) {
 	var messages []model.Message
 	var err error
 
 	if before != nil {
 		query := `
-			SELECT m.id, m.room_id, m.user_id, u.name as username, m.seq_no, m.content, m.message_type, m.extra_data, m.metadata, 
-				   m.edited, m.created_at, m.updated_at
+			SELECT ` + messageColumns + `
 			FROM messages m
-			LEFT JOIN users u ON m.user_id = u.id
-			WHERE m.room_id = $1 AND m.deleted_at IS NULL 
-				  AND m.created_at < (SELECT created_at FROM messages WHERE id = $2)
-			ORDER BY m.created_at DESC
+			` + messageJoins + `
+			WHERE m.room_id = $1 AND m.deleted_at IS NULL AND m.parent_message_id IS NULL
+				  AND m.seq_no < (SELECT seq_no FROM messages WHERE id = $2)
+			ORDER BY m.seq_no DESC
 			LIMIT $3
 		`
 		err = r.db.SelectContext(ctx, &messages, query, roomID, *before, limit)
 	} else {
 		query := `
-			SELECT m.id, m.room_id, m.user_id, u.name as username, m.seq_no, m.content, m.message_type, m.extra_data, m.metadata, 
-				   m.edited, m.created_at, m.updated_at
+			SELECT ` + messageColumns + `
 			FROM messages m
-			LEFT JOIN users u ON m.user_id = u.id
-			WHERE m.room_id = $1 AND m.deleted_at IS NULL
-			ORDER BY m.created_at DESC
+			` + messageJoins + `
+			WHERE m.room_id = $1 AND m.deleted_at IS NULL AND m.parent_message_id IS NULL
+			ORDER BY m.seq_no DESC
 			LIMIT $2
 		`
 		err = r.db.SelectContext(ctx, &messages, query, roomID, limit)
@@ -141,37 +233,253 @@ func (r *messageRepository) GetByRoomID(ctx context.Context, roomID uuid.UUID, l
 		messagePtrs[i] = &messages[i]
 	}
 
+	if err := r.loadReactions(ctx, messagePtrs); err != nil {
+		return nil, err
+	}
+	return messagePtrs, nil
+}
+
+// GetByRoomIDBeforeSeq returns the newest top-level messages of a room with
+// a sequence number lower than beforeSeq, oldest first.
+func (r *messageRepository) GetByRoomIDBeforeSeq(ctx context.Context, roomID uuid.UUID, beforeSeq int, limit int) ([]*model.Message, error) {
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages m
+		` + messageJoins + `
+		WHERE m.room_id = $1 AND m.deleted_at IS NULL AND m.parent_message_id IS NULL AND m.seq_no < $2
+		ORDER BY m.seq_no DESC
+		LIMIT $3
+	`
+
+	var messages []model.Message
+	err := r.db.SelectContext(ctx, &messages, query, roomID, beforeSeq, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reverse to get chronological order
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+
+	messagePtrs := make([]*model.Message, len(messages))
+	for i := range messages {
+		messagePtrs[i] = &messages[i]
+	}
+
+	if err := r.loadReactions(ctx, messagePtrs); err != nil {
+		return nil, err
+	}
+	return messagePtrs, nil
+}
+
+// GetByRoomIDAfterSeq returns the messages of a room with a sequence number
+// greater than afterSeq, oldest first. Thread replies are only included when
+// includeReplies is set, as realtime replay needs them but the timeline does
+// not.
+func (r *messageRepository) GetByRoomIDAfterSeq(ctx context.Context, roomID uuid.UUID, afterSeq int, limit int, includeReplies bool) ([]*model.Message, error) {
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages m
+		` + messageJoins + `
+		WHERE m.room_id = $1 AND m.deleted_at IS NULL AND m.seq_no > $2
+			  AND ($4 OR m.parent_message_id IS NULL)
+		ORDER BY m.seq_no ASC
+		LIMIT $3
+	`
+
+	var messages []model.Message
+	err := r.db.SelectContext(ctx, &messages, query, roomID, afterSeq, limit, includeReplies)
+	if err != nil {
+		return nil, err
+	}
+
+	messagePtrs := make([]*model.Message, len(messages))
+	for i := range messages {
+		messagePtrs[i] = &messages[i]
+	}
+
+	if err := r.loadReactions(ctx, messagePtrs); err != nil {
+		return nil, err
+	}
 	return messagePtrs, nil
 }
 
-func (r *messageRepository) Update(ctx context.Context, id uuid.UUID, content string) error {
+// Update replaces the message content and keeps the previous content as a
+// revision attributed to editorID.
+func (r *messageRepository) Update(ctx context.Context, id, editorID uuid.UUID, content string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var previous model.Message
+	err = tx.GetContext(ctx, &previous, "SELECT room_id, seq_no, content FROM messages WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", id)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
 	query := `
         UPDATE messages
         SET content = $1, edited = true, updated_at = $2
-        WHERE id = $3 AND deleted_at IS NULL
+        WHERE id = $3
     `
+	if _, err := tx.ExecContext(ctx, query, content, time.Now(), id); err != nil {
+		return err
+	}
 
-	_, err := r.db.ExecContext(ctx, query, content, time.Now(), id)
-	return err
+	if err := insertRevision(ctx, tx, id, editorID, model.RevisionActionEdit, previous.Content, &content); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	publishEvent(ctx, r.publisher, events.NewMessageEvent(events.MessageUpdated, previous.RoomID, id, previous.SeqNo))
+	return nil
 }
 
-func (r *messageRepository) Delete(ctx context.Context, id uuid.UUID) error {
+// Delete soft deletes the message and records a revision attributed to
+// editorID. Deleting a reply also takes it out of its root's reply rollup.
+func (r *messageRepository) Delete(ctx context.Context, id, editorID uuid.UUID) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var deleted model.Message
 	query := `
         UPDATE messages
         SET deleted_at = $1
-        WHERE id = $2
+        WHERE id = $2 AND deleted_at IS NULL
+        RETURNING id, room_id, seq_no, content, parent_message_id
     `
+	err = tx.GetContext(ctx, &deleted, query, time.Now(), id)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
 
-	_, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	if err := insertRevision(ctx, tx, id, editorID, model.RevisionActionDelete, deleted.Content, nil); err != nil {
+		return err
+	}
+
+	var root *model.Message
+	if deleted.ParentMessageID != nil {
+		root = &model.Message{}
+		rollup := `
+            UPDATE messages
+            SET reply_count = GREATEST(reply_count - 1, 0),
+                last_reply_at = (SELECT MAX(created_at) FROM messages WHERE parent_message_id = $1 AND deleted_at IS NULL)
+            WHERE id = $1
+            RETURNING id, room_id, seq_no
+        `
+		if err := tx.GetContext(ctx, root, rollup, *deleted.ParentMessageID); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	publishEvent(ctx, r.publisher, events.NewMessageEvent(events.MessageDeleted, deleted.RoomID, deleted.ID, deleted.SeqNo))
+	if root != nil {
+		publishEvent(ctx, r.publisher, events.NewMessageEvent(events.MessageUpdated, root.RoomID, root.ID, root.SeqNo))
+	}
+	return nil
+}
+
+func insertRevision(ctx context.Context, tx *sqlx.Tx, messageID, editorID uuid.UUID, action, previousContent string, newContent *string) error {
+	query := `
+        INSERT INTO message_revisions (message_id, editor_id, action, previous_content, new_content)
+        VALUES ($1, $2, $3, $4, $5)
+    `
+	_, err := tx.ExecContext(ctx, query, messageID, editorID, action, previousContent, newContent)
 	return err
 }
 
+// GetRevisions returns the edit and delete history of a message in a room,
+// oldest first. Deleted messages keep their history.
+func (r *messageRepository) GetRevisions(ctx context.Context, roomID, messageID uuid.UUID) ([]*model.MessageRevision, error) {
+	query := `
+        SELECT mr.id, mr.message_id, mr.editor_id, u.name as editor_name, mr.action,
+               mr.previous_content, mr.new_content, mr.created_at
+        FROM message_revisions mr
+        JOIN messages m ON mr.message_id = m.id
+        LEFT JOIN users u ON mr.editor_id = u.id
+        WHERE mr.message_id = $1 AND m.room_id = $2
+        ORDER BY mr.created_at
+    `
+
+	revisions := []*model.MessageRevision{}
+	err := r.db.SelectContext(ctx, &revisions, query, messageID, roomID)
+	return revisions, err
+}
+
+// GetThread returns the replies to a root message, paginated like
+// GetByRoomID: the newest replies with a lower seq_no than the given one,
+// oldest first.
+func (r *messageRepository) GetThread(ctx context.Context, rootID uuid.UUID, limit int, before *uuid.UUID) ([]*model.Message, error) {
+	var messages []model.Message
+	var err error
+
+	if before != nil {
+		query := `
+			SELECT ` + messageColumns + `
+			FROM messages m
+			` + messageJoins + `
+			WHERE m.parent_message_id = $1 AND m.deleted_at IS NULL
+				  AND m.seq_no < (SELECT seq_no FROM messages WHERE id = $2)
+			ORDER BY m.seq_no DESC
+			LIMIT $3
+		`
+		err = r.db.SelectContext(ctx, &messages, query, rootID, *before, limit)
+	} else {
+		query := `
+			SELECT ` + messageColumns + `
+			FROM messages m
+			` + messageJoins + `
+			WHERE m.parent_message_id = $1 AND m.deleted_at IS NULL
+			ORDER BY m.seq_no DESC
+			LIMIT $2
+		`
+		err = r.db.SelectContext(ctx, &messages, query, rootID, limit)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Reverse to get chronological order
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+
+	messagePtrs := make([]*model.Message, len(messages))
+	for i := range messages {
+		messagePtrs[i] = &messages[i]
+	}
+
+	if err := r.loadReactions(ctx, messagePtrs); err != nil {
+		return nil, err
+	}
+	return messagePtrs, nil
+}
+
 func (r *messageRepository) Search(ctx context.Context, roomID uuid.UUID, searchTerm string, limit int) ([]*model.Message, error) {
 	query := `
-        SELECT m.id, m.room_id, m.user_id, u.name as username, m.seq_no, m.content, m.message_type, m.extra_data, m.metadata,
-               m.edited, m.created_at, m.updated_at
+        SELECT ` + messageColumns + `
         FROM messages m
-        LEFT JOIN users u ON m.user_id = u.id
+        ` + messageJoins + `
         WHERE m.room_id = $1
               AND m.deleted_at IS NULL
               AND to_tsvector('english', m.content) @@ plainto_tsquery('english', $2)
@@ -190,6 +498,42 @@ func (r *messageRepository) Search(ctx context.Context, roomID uuid.UUID, search
 		messagePtrs[i] = &messages[i]
 	}
 
+	if err := r.loadReactions(ctx, messagePtrs); err != nil {
+		return nil, err
+	}
+	return messagePtrs, nil
+}
+
+// GetMentions returns the messages that mention the user, newest first,
+// across every room the user is still an active participant of. Messages
+// created at the same time are ordered by ID, so pages never overlap.
+func (r *messageRepository) GetMentions(ctx context.Context, userID uuid.UUID, limit int, before *uuid.UUID) ([]*model.Message, error) {
+	query := `
+        SELECT ` + messageColumns + `
+        FROM messages m
+        JOIN room_participants rp ON rp.room_id = m.room_id AND rp.user_id = $1 AND rp.is_active = true
+        ` + messageJoins + `
+        WHERE m.deleted_at IS NULL
+              AND m.metadata->'mentions' @> jsonb_build_array($2::text)
+              AND ($3::uuid IS NULL OR (m.created_at, m.id) < (SELECT created_at, id FROM messages WHERE id = $3))
+        ORDER BY m.created_at DESC, m.id DESC
+        LIMIT $4
+    `
+
+	var messages []model.Message
+	err := r.db.SelectContext(ctx, &messages, query, userID, userID.String(), before, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	messagePtrs := make([]*model.Message, len(messages))
+	for i := range messages {
+		messagePtrs[i] = &messages[i]
+	}
+
+	if err := r.loadReactions(ctx, messagePtrs); err != nil {
+		return nil, err
+	}
 	return messagePtrs, nil
 }
 
@@ -214,16 +558,152 @@ func (r *messageRepository) GetMessageWithAttachments(ctx context.Context, id uu
 	}
 
 	message.Attachments = attachments
+
+	if err := r.loadReactions(ctx, []*model.Message{message}); err != nil {
+		return nil, err
+	}
+
 	return message, nil
 }
 
+// loadReactions sets the reactions of the messages from their
+// message_reactions rows, aggregated per emoji in the order each emoji was
+// first used.
+func (r *messageRepository) loadReactions(ctx context.Context, messages []*model.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(messages))
+	for i, message := range messages {
+		ids[i] = message.ID.String()
+	}
+
+	query := `
+        SELECT message_id, emoji, user_id
+        FROM message_reactions
+        WHERE message_id = ANY($1::uuid[])
+        ORDER BY created_at
+    `
+
+	var rows []struct {
+		MessageID uuid.UUID `db:"message_id"`
+		Emoji     string    `db:"emoji"`
+		UserID    uuid.UUID `db:"user_id"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, query, pq.Array(ids)); err != nil {
+		return err
+	}
+
+	reactions := make(map[uuid.UUID][]model.Reaction)
+	index := make(map[uuid.UUID]map[string]int)
+	for _, row := range rows {
+		if index[row.MessageID] == nil {
+			index[row.MessageID] = make(map[string]int)
+		}
+		i, ok := index[row.MessageID][row.Emoji]
+		if !ok {
+			i = len(reactions[row.MessageID])
+			index[row.MessageID][row.Emoji] = i
+			reactions[row.MessageID] = append(reactions[row.MessageID], model.Reaction{Emoji: row.Emoji})
+		}
+		reaction := &reactions[row.MessageID][i]
+		reaction.UserIDs = append(reaction.UserIDs, row.UserID)
+		reaction.Count++
+	}
+
+	// message_reactions is the source of truth; reactions left in a
+	// message's stored metadata are ignored.
+	for _, message := range messages {
+		if message.Metadata == nil {
+			if len(reactions[message.ID]) == 0 {
+				continue
+			}
+			message.Metadata = &model.MessageMetadata{}
+		}
+		message.Metadata.Reactions = reactions[message.ID]
+	}
+	return nil
+}
+
 func (r *messageRepository) UpdateMetadata(ctx context.Context, id uuid.UUID, metadata *model.MessageMetadata) error {
 	query := `
         UPDATE messages
         SET metadata = $1, updated_at = $2
         WHERE id = $3 AND deleted_at IS NULL
+        RETURNING room_id, seq_no
     `
 
-	_, err := r.db.ExecContext(ctx, query, metadata, time.Now(), id)
+	_, err := r.execAndPublish(ctx, events.MessageUpdated, id, query, metadata, time.Now(), id)
 	return err
-}
\ No newline at end of file
+}
+
+// Pin pins a live message. It reports false when the message was already
+// pinned or does not exist.
+func (r *messageRepository) Pin(ctx context.Context, id, pinnedBy uuid.UUID) (bool, error) {
+	query := `
+        UPDATE messages
+        SET pinned_at = NOW(), pinned_by = $1
+        WHERE id = $2 AND pinned_at IS NULL AND deleted_at IS NULL
+        RETURNING room_id, seq_no
+    `
+
+	return r.execAndPublish(ctx, events.MessageUpdated, id, query, pinnedBy, id)
+}
+
+// Unpin unpins a message. It reports false when the message was not pinned.
+func (r *messageRepository) Unpin(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `
+        UPDATE messages
+        SET pinned_at = NULL, pinned_by = NULL
+        WHERE id = $1 AND pinned_at IS NOT NULL
+        RETURNING room_id, seq_no
+    `
+
+	return r.execAndPublish(ctx, events.MessageUpdated, id, query, id)
+}
+
+// GetPinned returns the room's pinned messages, most recently pinned first.
+func (r *messageRepository) GetPinned(ctx context.Context, roomID uuid.UUID) ([]*model.Message, error) {
+	query := `
+        SELECT ` + messageColumns + `
+        FROM messages m
+        ` + messageJoins + `
+        WHERE m.room_id = $1 AND m.pinned_at IS NOT NULL AND m.deleted_at IS NULL
+        ORDER BY m.pinned_at DESC
+    `
+
+	var messages []model.Message
+	err := r.db.SelectContext(ctx, &messages, query, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	messagePtrs := make([]*model.Message, len(messages))
+	for i := range messages {
+		messagePtrs[i] = &messages[i]
+	}
+
+	if err := r.loadReactions(ctx, messagePtrs); err != nil {
+		return nil, err
+	}
+	return messagePtrs, nil
+}
+
+// execAndPublish runs a single-message UPDATE returning room_id and seq_no,
+// and announces the change when a row was affected. It reports whether one
+// was.
+func (r *messageRepository) execAndPublish(ctx context.Context, eventType events.Type, id uuid.UUID, query string, args ...interface{}) (bool, error) {
+	var roomID uuid.UUID
+	var seqNo int
+	err := r.db.QueryRowxContext(ctx, query, args...).Scan(&roomID, &seqNo)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	publishEvent(ctx, r.publisher, events.NewMessageEvent(eventType, roomID, id, seqNo))
+	return true, nil
+}
@@ -3,10 +3,14 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
+
+	"livekit-consulting/backend/internal/events"
 	"livekit-consulting/backend/internal/model"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type ParticipantRepository interface {
@@ -16,16 +20,21 @@ type ParticipantRepository interface {
 	CountByRoomID(ctx context.Context, roomID uuid.UUID) (int, error)
 	UserHasAccess(ctx context.Context, roomID, userID uuid.UUID) (bool, error)
 	GetByRoomID(ctx context.Context, roomID uuid.UUID) ([]*model.RoomParticipant, error)
+	GetByRoomAndUsernames(ctx context.Context, roomID uuid.UUID, usernames []string) ([]*model.RoomParticipant, error)
 	Delete(ctx context.Context, participantID uuid.UUID) error
 	UpdateLastRead(ctx context.Context, roomID, userID uuid.UUID, lastReadSeqNo int) error
+	UpdateLastSeen(ctx context.Context, roomID, userID uuid.UUID) error
+	GetByRoomAndIdentity(ctx context.Context, roomID uuid.UUID, identity string) (*model.RoomParticipant, error)
+	MarkJoined(ctx context.Context, participantID uuid.UUID, identity string, joinedAt time.Time) error
 }
 
 type participantRepository struct {
-	db *sqlx.DB
+	db        *sqlx.DB
+	publisher events.Publisher
 }
 
-func NewParticipantRepository(db *sqlx.DB) ParticipantRepository {
-	return &participantRepository{db: db}
+func NewParticipantRepository(db *sqlx.DB, publisher events.Publisher) ParticipantRepository {
+	return &participantRepository{db: db, publisher: publisher}
 }
 
 func (r *participantRepository) Create(ctx context.Context, participant *model.RoomParticipant) error {
@@ -34,12 +43,18 @@ func (r *participantRepository) Create(ctx context.Context, participant *model.R
 		VALUES ($1, $2, $3, $4, $5)
 		RETURNING id, created_at, is_active
 	`
-	return r.db.QueryRowxContext(ctx, query, participant.RoomID, participant.UserID, participant.Email, participant.Name, participant.Role).Scan(&participant.ID, &participant.CreatedAt, &participant.IsActive)
+	err := r.db.QueryRowxContext(ctx, query, participant.RoomID, participant.UserID, participant.Email, participant.Name, participant.Role).Scan(&participant.ID, &participant.CreatedAt, &participant.IsActive)
+	if err != nil {
+		return err
+	}
+
+	publishEvent(ctx, r.publisher, events.NewRoomEvent(events.ParticipantAdded, participant.RoomID, participantEventData(participant)))
+	return nil
 }
 
 func (r *participantRepository) GetByRoomAndEmail(ctx context.Context, roomID uuid.UUID, email string) (*model.RoomParticipant, error) {
 	var participant model.RoomParticipant
-	query := `SELECT * FROM room_participants WHERE room_id = $1 AND email = $2`
+	query := `SELECT * FROM room_participants WHERE room_id = $1 AND LOWER(email) = LOWER($2)`
 	err := r.db.GetContext(ctx, &participant, query, roomID, email)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -84,10 +99,33 @@ func (r *participantRepository) GetByRoomID(ctx context.Context, roomID uuid.UUI
     return participants, err
 }
 
+// GetByRoomAndUsernames returns the active participants whose user account
+// has one of the given usernames, compared case-insensitively.
+func (r *participantRepository) GetByRoomAndUsernames(ctx context.Context, roomID uuid.UUID, usernames []string) ([]*model.RoomParticipant, error) {
+	var participants []*model.RoomParticipant
+	query := `
+		SELECT rp.*
+		FROM room_participants rp
+		JOIN users u ON u.id = rp.user_id
+		WHERE rp.room_id = $1 AND rp.is_active = true AND lower(u.username) = ANY($2)
+	`
+	err := r.db.SelectContext(ctx, &participants, query, roomID, pq.Array(usernames))
+	return participants, err
+}
+
 func (r *participantRepository) Delete(ctx context.Context, participantID uuid.UUID) error {
-    query := `UPDATE room_participants SET is_active = false WHERE id = $1`
-    _, err := r.db.ExecContext(ctx, query, participantID)
-    return err
+    var participant model.RoomParticipant
+    query := `UPDATE room_participants SET is_active = false WHERE id = $1 RETURNING *`
+    err := r.db.GetContext(ctx, &participant, query, participantID)
+    if err == sql.ErrNoRows {
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+
+    publishEvent(ctx, r.publisher, events.NewRoomEvent(events.ParticipantRemoved, participant.RoomID, participantEventData(&participant)))
+    return nil
 }
 
 func (r *participantRepository) UpdateLastRead(ctx context.Context, roomID, userID uuid.UUID, lastReadSeqNo int) error {
@@ -100,3 +138,46 @@ func (r *participantRepository) UpdateLastRead(ctx context.Context, roomID, user
 	return err
 }
 
+// UpdateLastSeen marks the user as currently connected to the room.
+func (r *participantRepository) UpdateLastSeen(ctx context.Context, roomID, userID uuid.UUID) error {
+	query := `
+		UPDATE room_participants
+		SET last_seen_at = NOW()
+		WHERE room_id = $1 AND user_id = $2
+	`
+	_, err := r.db.ExecContext(ctx, query, roomID, userID)
+	return err
+}
+
+// GetByRoomAndIdentity finds the participant behind a LiveKit identity: the
+// identity recorded when they last joined the call, or else their email,
+// which tokens are issued for.
+func (r *participantRepository) GetByRoomAndIdentity(ctx context.Context, roomID uuid.UUID, identity string) (*model.RoomParticipant, error) {
+	var participant model.RoomParticipant
+	query := `
+		SELECT * FROM room_participants
+		WHERE room_id = $1 AND (livekit_identity = $2 OR LOWER(email) = LOWER($2))
+		ORDER BY livekit_identity = $2 DESC NULLS LAST
+		LIMIT 1
+	`
+	err := r.db.GetContext(ctx, &participant, query, roomID, identity)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &participant, nil
+}
+
+// MarkJoined records the participant's LiveKit identity and, the first time
+// they join the call, when that was.
+func (r *participantRepository) MarkJoined(ctx context.Context, participantID uuid.UUID, identity string, joinedAt time.Time) error {
+	query := `
+		UPDATE room_participants
+		SET livekit_identity = $1, joined_at = COALESCE(joined_at, $2)
+		WHERE id = $3
+	`
+	_, err := r.db.ExecContext(ctx, query, identity, joinedAt, participantID)
+	return err
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"livekit-consulting/backend/internal/events"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type ReactionRepository interface {
+	Add(ctx context.Context, messageID, userID uuid.UUID, emoji string) error
+	Remove(ctx context.Context, messageID, userID uuid.UUID, emoji string) error
+}
+
+type reactionRepository struct {
+	db        *sqlx.DB
+	publisher events.Publisher
+}
+
+func NewReactionRepository(db *sqlx.DB, publisher events.Publisher) ReactionRepository {
+	return &reactionRepository{db: db, publisher: publisher}
+}
+
+// Add records the user's reaction. Reacting twice with the same emoji is a
+// no-op, so concurrent reactions never overwrite each other.
+func (r *reactionRepository) Add(ctx context.Context, messageID, userID uuid.UUID, emoji string) error {
+	query := `
+        WITH added AS (
+            INSERT INTO message_reactions (message_id, user_id, emoji)
+            VALUES ($1, $2, $3)
+            ON CONFLICT DO NOTHING
+            RETURNING message_id
+        )
+        SELECT m.room_id, m.seq_no FROM messages m JOIN added ON m.id = added.message_id
+    `
+	return r.execAndPublish(ctx, events.MessageReactionAdded, messageID, userID, emoji, query)
+}
+
+func (r *reactionRepository) Remove(ctx context.Context, messageID, userID uuid.UUID, emoji string) error {
+	query := `
+        WITH removed AS (
+            DELETE FROM message_reactions
+            WHERE message_id = $1 AND user_id = $2 AND emoji = $3
+            RETURNING message_id
+        )
+        SELECT m.room_id, m.seq_no FROM messages m JOIN removed ON m.id = removed.message_id
+    `
+	return r.execAndPublish(ctx, events.MessageReactionRemoved, messageID, userID, emoji, query)
+}
+
+func (r *reactionRepository) execAndPublish(ctx context.Context, eventType events.Type, messageID, userID uuid.UUID, emoji, query string) error {
+	var roomID uuid.UUID
+	var seqNo int
+	err := r.db.QueryRowxContext(ctx, query, messageID, userID, emoji).Scan(&roomID, &seqNo)
+	if err == sql.ErrNoRows {
+		// Nothing changed.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	event := events.NewMessageEvent(eventType, roomID, messageID, seqNo)
+	event.Data, _ = json.Marshal(map[string]interface{}{"emoji": emoji, "user_id": userID})
+	publishEvent(ctx, r.publisher, event)
+	return nil
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"livekit-consulting/backend/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type RedactionPolicyRepository interface {
+	GetByRoomID(ctx context.Context, roomID uuid.UUID) (*model.TranscriptRedactionPolicy, error)
+	Upsert(ctx context.Context, policy *model.TranscriptRedactionPolicy) error
+}
+
+type redactionPolicyRepository struct {
+	db *sqlx.DB
+}
+
+func NewRedactionPolicyRepository(db *sqlx.DB) RedactionPolicyRepository {
+	return &redactionPolicyRepository{db: db}
+}
+
+// GetByRoomID returns the room's redaction policy, or nil when the room has
+// not set one.
+func (r *redactionPolicyRepository) GetByRoomID(ctx context.Context, roomID uuid.UUID) (*model.TranscriptRedactionPolicy, error) {
+	var policy model.TranscriptRedactionPolicy
+	err := r.db.GetContext(ctx, &policy, "SELECT * FROM transcript_redaction_policies WHERE room_id = $1", roomID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *redactionPolicyRepository) Upsert(ctx context.Context, policy *model.TranscriptRedactionPolicy) error {
+	query := `
+		INSERT INTO transcript_redaction_policies (room_id, enabled, categories, custom_patterns, updated_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (room_id) DO UPDATE
+		SET enabled = EXCLUDED.enabled,
+		    categories = EXCLUDED.categories,
+		    custom_patterns = EXCLUDED.custom_patterns,
+		    updated_by = EXCLUDED.updated_by,
+		    updated_at = NOW()
+		RETURNING created_at, updated_at
+	`
+	return r.db.QueryRowContext(ctx, query,
+		policy.RoomID, policy.Enabled, policy.Categories, policy.CustomPatterns, policy.UpdatedBy,
+	).Scan(&policy.CreatedAt, &policy.UpdatedAt)
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"livekit-consulting/backend/internal/events"
 	"livekit-consulting/backend/internal/model"
 	"time"
 
@@ -20,30 +21,33 @@ type RoomRepository interface {
 	GetRoomsByUser(ctx context.Context, userID uuid.UUID) ([]*model.Room, error)
 	UpdateLastRead(ctx context.Context, roomID, userID uuid.UUID) error
 	GetUnreadCount(ctx context.Context, roomID, userID uuid.UUID) (int, error)
+	UpdateSID(ctx context.Context, roomID uuid.UUID, sid string) error
+	UpdateTokenTTL(ctx context.Context, room *model.Room) error
 }
 
 type roomRepository struct {
-	db *sqlx.DB
+	db        *sqlx.DB
+	publisher events.Publisher
 }
 
-func NewRoomRepository(db *sqlx.DB) RoomRepository {
-	return &roomRepository{db: db}
+func NewRoomRepository(db *sqlx.DB, publisher events.Publisher) RoomRepository {
+	return &roomRepository{db: db, publisher: publisher}
 }
 
 func (r *roomRepository) Create(ctx context.Context, room *model.Room) error {
 	query := `
-        INSERT INTO rooms (room_name, description, owner_id, livekit_room_name, room_sid)
-        VALUES ($1, $2, $3, $4, $5)
+        INSERT INTO rooms (room_name, description, owner_id, livekit_room_name, room_sid, token_ttl_seconds)
+        VALUES ($1, $2, $3, $4, $5, $6)
         RETURNING id, created_at, updated_at, is_active
     `
-	return r.db.QueryRowxContext(ctx, query, room.RoomName, room.Description, room.OwnerID, room.LiveKitRoomName, room.RoomSID).Scan(&room.ID, &room.CreatedAt, &room.UpdatedAt, &room.IsActive)
+	return r.db.QueryRowxContext(ctx, query, room.RoomName, room.Description, room.OwnerID, room.LiveKitRoomName, room.RoomSID, room.TokenTTLSeconds).Scan(&room.ID, &room.CreatedAt, &room.UpdatedAt, &room.IsActive)
 }
 
 func (r *roomRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Room, error) {
 	var room model.Room
 	query := `
         SELECT id, room_name, room_sid, description, owner_id, livekit_room_name, 
-               metadata, created_at, updated_at, is_active, last_message_seq, last_message_at
+               metadata, created_at, updated_at, is_active, last_message_seq, last_message_at, token_ttl_seconds
         FROM rooms
         WHERE id = $1 AND is_active = true
     `
@@ -58,7 +62,7 @@ func (r *roomRepository) GetByName(ctx context.Context, name string) (*model.Roo
 	var room model.Room
 	query := `
         SELECT id, room_name, room_sid, description, owner_id, livekit_room_name, 
-               metadata, created_at, updated_at, is_active, last_message_seq, last_message_at
+               metadata, created_at, updated_at, is_active, last_message_seq, last_message_at, token_ttl_seconds
         FROM rooms
         WHERE livekit_room_name = $1 AND is_active = true
     `
@@ -73,7 +77,7 @@ func (r *roomRepository) GetByOwnerID(ctx context.Context, ownerID uuid.UUID) ([
 	var rooms []*model.Room
 	query := `
         SELECT id, room_name, room_sid, description, owner_id, livekit_room_name, 
-               metadata, created_at, updated_at, is_active, last_message_seq, last_message_at
+               metadata, created_at, updated_at, is_active, last_message_seq, last_message_at, token_ttl_seconds
         FROM rooms
         WHERE owner_id = $1 AND is_active = true
         ORDER BY created_at DESC
@@ -89,20 +93,30 @@ func (r *roomRepository) Update(ctx context.Context, room *model.Room) error {
         WHERE id = $5
     `
 	_, err := r.db.ExecContext(ctx, query, room.RoomName, room.Description, room.LiveKitRoomName, room.RoomSID, room.ID)
-	return err
+	if err != nil {
+		return err
+	}
+
+	publishEvent(ctx, r.publisher, events.NewRoomEvent(events.RoomUpdated, room.ID, roomEventData(room)))
+	return nil
 }
 
 func (r *roomRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `UPDATE rooms SET is_active = false, updated_at = NOW() WHERE id = $1`
 	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+	if err != nil {
+		return err
+	}
+
+	publishEvent(ctx, r.publisher, events.NewRoomEvent(events.RoomDeleted, id, nil))
+	return nil
 }
 
 func (r *roomRepository) GetRoomsByUser(ctx context.Context, userID uuid.UUID) ([]*model.Room, error) {
 	var rooms []*model.Room
 	query := `
         SELECT r.id, r.room_name, r.room_sid, r.description, r.owner_id, r.livekit_room_name, 
-               r.metadata, r.created_at, r.updated_at, r.is_active, r.last_message_seq, r.last_message_at
+               r.metadata, r.created_at, r.updated_at, r.is_active, r.last_message_seq, r.last_message_at, r.token_ttl_seconds
         FROM rooms r
         JOIN room_participants rp ON r.id = rp.room_id
         WHERE rp.user_id = $1 AND r.is_active = true
@@ -138,3 +152,23 @@ func (r *roomRepository) GetUnreadCount(ctx context.Context, roomID, userID uuid
 	err := r.db.GetContext(ctx, &count, query, roomID, userID)
 	return count, err
 }
+
+// UpdateSID records the session ID LiveKit assigned to the room's current
+// call.
+func (r *roomRepository) UpdateSID(ctx context.Context, roomID uuid.UUID, sid string) error {
+	query := `UPDATE rooms SET room_sid = $1, updated_at = NOW() WHERE id = $2`
+	_, err := r.db.ExecContext(ctx, query, sid, roomID)
+	return err
+}
+
+// UpdateTokenTTL saves the room's LiveKit token lifetime.
+func (r *roomRepository) UpdateTokenTTL(ctx context.Context, room *model.Room) error {
+	query := `UPDATE rooms SET token_ttl_seconds = $1, updated_at = NOW() WHERE id = $2`
+	_, err := r.db.ExecContext(ctx, query, room.TokenTTLSeconds, room.ID)
+	if err != nil {
+		return err
+	}
+
+	publishEvent(ctx, r.publisher, events.NewRoomEvent(events.RoomUpdated, room.ID, roomEventData(room)))
+	return nil
+}
@@ -0,0 +1,292 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"livekit-consulting/backend/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type TranscriptRepository interface {
+	InsertSegments(ctx context.Context, segments []*model.TranscriptSegment) error
+	GetSegments(ctx context.Context, messageID uuid.UUID, afterIndex int, limit int) ([]*model.TranscriptSegment, error)
+	Search(ctx context.Context, roomID uuid.UUID, searchTerm string, limit int) ([]*model.TranscriptSearchHit, error)
+	SearchForUser(ctx context.Context, userID uuid.UUID, searchTerm string, limit int) ([]*model.TranscriptSearchHit, error)
+	Highlight(ctx context.Context, texts []string, searchTerm string) (map[int]string, error)
+	CorrectSegment(ctx context.Context, messageID uuid.UUID, segmentIndex int, editorID uuid.UUID, text string, speakerName *string) (*model.TranscriptSegment, error)
+	GetCurrentVersion(ctx context.Context, messageID uuid.UUID) (int, error)
+	GetSegmentsAtVersion(ctx context.Context, messageID uuid.UUID, version int) ([]*model.TranscriptSegment, error)
+	GetRevisions(ctx context.Context, messageID uuid.UUID) ([]*model.TranscriptSegmentRevision, error)
+}
+
+// transcriptSearchColumns is the select list of the transcript searches; it
+// expects segments aliased as s, their transcript message as m and rooms as
+// r. Snippets are left to Highlight, which runs on the redacted text.
+const transcriptSearchColumns = `
+        s.id, s.room_id, s.message_id, s.segment_index, s.speaker_identity, s.speaker_name, s.role,
+        s.start_ms, s.end_ms, s.text, s.version, s.edited_by, s.edited_at, s.created_at, r.room_name,
+        COALESCE((m.extra_data->'transcript'->>'session_start')::timestamptz, m.created_at) AS session_start`
+
+type transcriptRepository struct {
+	db *sqlx.DB
+}
+
+func NewTranscriptRepository(db *sqlx.DB) TranscriptRepository {
+	return &transcriptRepository{db: db}
+}
+
+// InsertSegments stores the segments of a transcript message. Segments that
+// are already stored are left alone, so ingesting the same transcript twice,
+// even concurrently, never undoes corrections made in between.
+func (r *transcriptRepository) InsertSegments(ctx context.Context, segments []*model.TranscriptSegment) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+        INSERT INTO transcript_segments (room_id, message_id, segment_index, speaker_identity, speaker_name, role, start_ms, end_ms, text)
+        VALUES (:room_id, :message_id, :segment_index, :speaker_identity, :speaker_name, :role, :start_ms, :end_ms, :text)
+        ON CONFLICT (message_id, segment_index) DO NOTHING
+    `
+	stmt, err := tx.PrepareNamedContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, segment := range segments {
+		if _, err := stmt.ExecContext(ctx, segment); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetSegments returns up to limit segments of a transcript with an index
+// greater than afterIndex, in transcript order.
+func (r *transcriptRepository) GetSegments(ctx context.Context, messageID uuid.UUID, afterIndex int, limit int) ([]*model.TranscriptSegment, error) {
+	query := `
+        SELECT id, room_id, message_id, segment_index, speaker_identity, speaker_name, role, start_ms, end_ms, text,
+               version, edited_by, edited_at, created_at
+        FROM transcript_segments
+        WHERE message_id = $1 AND segment_index > $2
+        ORDER BY segment_index ASC
+        LIMIT $3
+    `
+
+	var segments []*model.TranscriptSegment
+	err := r.db.SelectContext(ctx, &segments, query, messageID, afterIndex, limit)
+	return segments, err
+}
+
+// Search runs a full-text search over the transcripts of a room, best matches
+// first.
+func (r *transcriptRepository) Search(ctx context.Context, roomID uuid.UUID, searchTerm string, limit int) ([]*model.TranscriptSearchHit, error) {
+	query := `
+        SELECT ` + transcriptSearchColumns + `
+        FROM transcript_segments s
+        JOIN messages m ON m.id = s.message_id
+        JOIN rooms r ON r.id = s.room_id
+        WHERE s.room_id = $1
+              AND m.deleted_at IS NULL
+              AND to_tsvector('english', s.text) @@ plainto_tsquery('english', $2)
+        ORDER BY ts_rank(to_tsvector('english', s.text), plainto_tsquery('english', $2)) DESC, m.created_at DESC
+        LIMIT $3
+    `
+
+	var hits []*model.TranscriptSearchHit
+	err := r.db.SelectContext(ctx, &hits, query, roomID, searchTerm, limit)
+	return hits, err
+}
+
+// SearchForUser runs a full-text search over the transcripts of every room
+// the user is an active participant of, best matches first.
+func (r *transcriptRepository) SearchForUser(ctx context.Context, userID uuid.UUID, searchTerm string, limit int) ([]*model.TranscriptSearchHit, error) {
+	query := `
+        SELECT ` + transcriptSearchColumns + `
+        FROM transcript_segments s
+        JOIN room_participants rp ON rp.room_id = s.room_id AND rp.user_id = $1 AND rp.is_active = true
+        JOIN messages m ON m.id = s.message_id
+        JOIN rooms r ON r.id = s.room_id
+        WHERE m.deleted_at IS NULL
+              AND to_tsvector('english', s.text) @@ plainto_tsquery('english', $2)
+        ORDER BY ts_rank(to_tsvector('english', s.text), plainto_tsquery('english', $2)) DESC, m.created_at DESC
+        LIMIT $3
+    `
+
+	var hits []*model.TranscriptSearchHit
+	err := r.db.SelectContext(ctx, &hits, query, userID, searchTerm, limit)
+	return hits, err
+}
+
+// Highlight runs the search term against the given texts and returns, by
+// position, a snippet of each text that matches with the matching words
+// wrapped in <mark> tags. Texts that do not match are left out.
+func (r *transcriptRepository) Highlight(ctx context.Context, texts []string, searchTerm string) (map[int]string, error) {
+	query := `
+        SELECT t.ord - 1 AS position,
+               ts_headline('english', t.text, plainto_tsquery('english', $2),
+                           'StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15') AS snippet
+        FROM unnest($1::text[]) WITH ORDINALITY AS t(text, ord)
+        WHERE to_tsvector('english', t.text) @@ plainto_tsquery('english', $2)
+    `
+
+	var rows []struct {
+		Position int    `db:"position"`
+		Snippet  string `db:"snippet"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, query, pq.Array(texts), searchTerm); err != nil {
+		return nil, err
+	}
+
+	snippets := make(map[int]string, len(rows))
+	for _, row := range rows {
+		snippets[row.Position] = row.Snippet
+	}
+	return snippets, nil
+}
+
+// CorrectSegment replaces the text and, when speakerName is set, the speaker
+// name of a segment and records the correction as the transcript's next
+// version. It returns nil when the segment does not exist, and the segment
+// unchanged when nothing differs.
+func (r *transcriptRepository) CorrectSegment(ctx context.Context, messageID uuid.UUID, segmentIndex int, editorID uuid.UUID, text string, speakerName *string) (*model.TranscriptSegment, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Locking the transcript message serializes corrections, so versions are
+	// handed out without gaps or collisions.
+	if _, err := tx.ExecContext(ctx, "SELECT id FROM messages WHERE id = $1 FOR UPDATE", messageID); err != nil {
+		return nil, err
+	}
+
+	var segment model.TranscriptSegment
+	query := `
+        SELECT id, room_id, message_id, segment_index, speaker_identity, speaker_name, role, start_ms, end_ms, text,
+               version, edited_by, edited_at, created_at
+        FROM transcript_segments
+        WHERE message_id = $1 AND segment_index = $2
+    `
+	err = tx.GetContext(ctx, &segment, query, messageID, segmentIndex)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	newSpeakerName := segment.SpeakerName
+	if speakerName != nil {
+		newSpeakerName = *speakerName
+	}
+	if segment.Text == text && segment.SpeakerName == newSpeakerName {
+		return &segment, nil
+	}
+
+	var version int
+	err = tx.GetContext(ctx, &version, "SELECT COALESCE(MAX(version), 0) + 1 FROM transcript_segment_revisions WHERE message_id = $1", messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	query = `
+        INSERT INTO transcript_segment_revisions
+            (segment_id, message_id, version, editor_id, previous_text, new_text, previous_speaker_name, new_speaker_name)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+    `
+	_, err = tx.ExecContext(ctx, query, segment.ID, messageID, version, editorID, segment.Text, text, segment.SpeakerName, newSpeakerName)
+	if err != nil {
+		return nil, err
+	}
+
+	query = `
+        UPDATE transcript_segments
+        SET text = $1, speaker_name = $2, version = $3, edited_by = $4, edited_at = NOW()
+        WHERE id = $5
+        RETURNING edited_at
+    `
+	err = tx.QueryRowContext(ctx, query, text, newSpeakerName, version, editorID, segment.ID).Scan(&segment.EditedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	segment.Text = text
+	segment.SpeakerName = newSpeakerName
+	segment.Version = version
+	segment.EditedBy = &editorID
+	return &segment, nil
+}
+
+// GetCurrentVersion returns the latest version of a transcript, 0 when it has
+// never been corrected.
+func (r *transcriptRepository) GetCurrentVersion(ctx context.Context, messageID uuid.UUID) (int, error) {
+	var version int
+	err := r.db.GetContext(ctx, &version, "SELECT COALESCE(MAX(version), 0) FROM transcript_segment_revisions WHERE message_id = $1", messageID)
+	return version, err
+}
+
+// GetSegmentsAtVersion returns every segment of a transcript as it read at
+// the given version, in transcript order. A segment corrected after that
+// version reads as it did before its first later correction.
+func (r *transcriptRepository) GetSegmentsAtVersion(ctx context.Context, messageID uuid.UUID, version int) ([]*model.TranscriptSegment, error) {
+	query := `
+        SELECT s.id, s.room_id, s.message_id, s.segment_index, s.speaker_identity,
+               COALESCE(later.previous_speaker_name, s.speaker_name) AS speaker_name,
+               s.role, s.start_ms, s.end_ms,
+               COALESCE(later.previous_text, s.text) AS text,
+               COALESCE(earlier.version, 0) AS version,
+               earlier.editor_id AS edited_by, earlier.created_at AS edited_at,
+               s.created_at
+        FROM transcript_segments s
+        LEFT JOIN LATERAL (
+            SELECT previous_text, previous_speaker_name
+            FROM transcript_segment_revisions
+            WHERE segment_id = s.id AND version > $2
+            ORDER BY version ASC
+            LIMIT 1
+        ) later ON true
+        LEFT JOIN LATERAL (
+            SELECT version, editor_id, created_at
+            FROM transcript_segment_revisions
+            WHERE segment_id = s.id AND version <= $2
+            ORDER BY version DESC
+            LIMIT 1
+        ) earlier ON true
+        WHERE s.message_id = $1
+        ORDER BY s.segment_index ASC
+    `
+
+	var segments []*model.TranscriptSegment
+	err := r.db.SelectContext(ctx, &segments, query, messageID, version)
+	return segments, err
+}
+
+// GetRevisions returns every correction of a transcript, oldest first.
+func (r *transcriptRepository) GetRevisions(ctx context.Context, messageID uuid.UUID) ([]*model.TranscriptSegmentRevision, error) {
+	query := `
+        SELECT tr.id, tr.segment_id, tr.message_id, s.segment_index, tr.version, tr.editor_id, u.name as editor_name,
+               tr.previous_text, tr.new_text, tr.previous_speaker_name, tr.new_speaker_name, tr.created_at
+        FROM transcript_segment_revisions tr
+        JOIN transcript_segments s ON tr.segment_id = s.id
+        LEFT JOIN users u ON tr.editor_id = u.id
+        WHERE tr.message_id = $1
+        ORDER BY tr.version
+    `
+
+	revisions := []*model.TranscriptSegmentRevision{}
+	err := r.db.SelectContext(ctx, &revisions, query, messageID)
+	return revisions, err
+}
@@ -0,0 +1,385 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"livekit-consulting/backend/internal/events"
+	"livekit-consulting/backend/internal/model"
+	"livekit-consulting/backend/internal/repository"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Agent webhook event types.
+const (
+	AgentEventTranscriptUploaded = "transcript_uploaded"
+	AgentEventLiveCaption        = "live_caption"
+	AgentEventMeetingSummary     = "meeting_summary"
+	AgentEventActionItems        = "action_items"
+	AgentEventAgentJoined        = "agent_joined"
+	AgentEventAgentLeft          = "agent_left"
+)
+
+var (
+	// ErrUnsupportedAgentEvent is returned for events no handler is registered for.
+	ErrUnsupportedAgentEvent = errors.New("unsupported agent event type")
+	// ErrInvalidAgentEvent is returned when an event payload cannot be used.
+	ErrInvalidAgentEvent = errors.New("invalid agent event payload")
+)
+
+// AgentEventResult describes what handling an agent event produced. Message
+// is nil for events that are only relayed to clients.
+type AgentEventResult struct {
+	Message   *model.Message
+	Duplicate bool
+}
+
+// AgentEventHandler handles one agent webhook event type. body is the raw
+// request body, which every event shares the AgentEvent envelope of.
+type AgentEventHandler func(ctx context.Context, body []byte) (*AgentEventResult, error)
+
+// AgentEvent is the envelope common to all agent webhook events. EventID is
+// optional; when set, retries of the event are deduplicated on it. Agents
+// identify themselves with AgentIdentity and AgentName; without them the
+// default meeting assistant authors the resulting message.
+type AgentEvent struct {
+	Event         string `json:"event"`
+	EventID       string `json:"event_id"`
+	RoomName      string `json:"room_name"`
+	AgentIdentity string `json:"agent_identity"`
+	AgentName     string `json:"agent_name"`
+}
+
+func (e *AgentEvent) idempotencyKey() string {
+	if e.EventID == "" {
+		return ""
+	}
+	return "agent:" + e.Event + ":" + e.RoomName + ":" + e.EventID
+}
+
+// AgentEventDispatcher routes agent webhook deliveries to the handler
+// registered for their event type.
+type AgentEventDispatcher struct {
+	handlers map[string]AgentEventHandler
+}
+
+func NewAgentEventDispatcher() *AgentEventDispatcher {
+	return &AgentEventDispatcher{handlers: make(map[string]AgentEventHandler)}
+}
+
+// Register sets the handler for an event type, replacing any earlier one.
+func (d *AgentEventDispatcher) Register(event string, handler AgentEventHandler) {
+	d.handlers[event] = handler
+}
+
+func (d *AgentEventDispatcher) Dispatch(ctx context.Context, body []byte) (*AgentEventResult, error) {
+	var envelope AgentEvent
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAgentEvent, err)
+	}
+
+	handler, ok := d.handlers[envelope.Event]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAgentEvent, envelope.Event)
+	}
+	if envelope.RoomName == "" {
+		return nil, fmt.Errorf("%w: room_name is required", ErrInvalidAgentEvent)
+	}
+
+	return handler(ctx, body)
+}
+
+// LiveCaptionPayload is a caption the agent produced while the meeting runs.
+type LiveCaptionPayload struct {
+	AgentEvent
+	SpeakerIdentity string `json:"speaker_identity"`
+	SpeakerName     string `json:"speaker_name"`
+	Text            string `json:"text"`
+	IsFinal         bool   `json:"is_final"`
+	Timestamp       string `json:"timestamp"`
+}
+
+type MeetingSummaryPayload struct {
+	AgentEvent
+	Summary      string   `json:"summary"`
+	KeyPoints    []string `json:"key_points"`
+	SessionStart string   `json:"session_start"`
+	SessionEnd   string   `json:"session_end"`
+}
+
+type ActionItemsPayload struct {
+	AgentEvent
+	Items        []model.ActionItem `json:"items"`
+	SessionStart string             `json:"session_start"`
+}
+
+// AgentEventService implements the built-in agent event handlers.
+type AgentEventService struct {
+	messageService    *MessageService
+	transcriptService *TranscriptService
+	agentService      *AgentService
+	redactionService  *RedactionService
+	sessionService    *MeetingSessionService
+	roomRepo          repository.RoomRepository
+	publisher         events.Publisher
+}
+
+func NewAgentEventService(
+	messageService *MessageService,
+	transcriptService *TranscriptService,
+	agentService *AgentService,
+	redactionService *RedactionService,
+	sessionService *MeetingSessionService,
+	roomRepo repository.RoomRepository,
+	publisher events.Publisher,
+) *AgentEventService {
+	return &AgentEventService{
+		messageService:    messageService,
+		transcriptService: transcriptService,
+		agentService:      agentService,
+		redactionService:  redactionService,
+		sessionService:    sessionService,
+		roomRepo:          roomRepo,
+		publisher:         publisher,
+	}
+}
+
+// HandleTranscriptUploaded posts the transcript message, links it to the
+// meeting session it was recorded in and stores its segments.
+func (s *AgentEventService) HandleTranscriptUploaded(ctx context.Context, body []byte) (*AgentEventResult, error) {
+	var payload AgentWebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAgentEvent, err)
+	}
+
+	var envelope AgentEvent
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAgentEvent, err)
+	}
+	agent, err := s.agentService.ResolveAgent(ctx, envelope.AgentIdentity, envelope.AgentName)
+	if err != nil {
+		return nil, err
+	}
+
+	message, created, err := s.messageService.CreateTranscriptMessage(ctx, &payload, agent.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if created {
+		// The transcript message is already posted; the session link,
+		// segments and the digest are derived from it, so a failure here
+		// must not make the agent retry.
+		if _, err := s.sessionService.LinkTranscript(ctx, message); err != nil {
+			log.Error().
+				Err(err).
+				Str("room_name", payload.RoomName).
+				Str("message_id", message.ID.String()).
+				Msg("Failed to link transcript to its meeting session")
+		}
+		if err := s.transcriptService.IngestTranscript(ctx, message); err != nil {
+			log.Error().
+				Err(err).
+				Str("room_name", payload.RoomName).
+				Str("message_id", message.ID.String()).
+				Msg("Failed to ingest transcript segments")
+		} else if err := s.postTranscriptDigest(ctx, payload.RoomName, agent, message); err != nil {
+			log.Error().
+				Err(err).
+				Str("room_name", payload.RoomName).
+				Str("message_id", message.ID.String()).
+				Msg("Failed to post transcript summary")
+		}
+	}
+
+	return &AgentEventResult{Message: message, Duplicate: !created}, nil
+}
+
+// postTranscriptDigest follows a transcript message up with its extractive
+// summary, action items and talk time, posted by the same agent.
+func (s *AgentEventService) postTranscriptDigest(ctx context.Context, roomName string, agent *model.Agent, transcriptMessage *model.Message) error {
+	digest, extraData, err := s.transcriptService.DigestTranscript(ctx, transcriptMessage)
+	if err != nil {
+		return err
+	}
+
+	idempotencyKey := "digest:" + transcriptMessage.ID.String()
+	_, _, err = s.messageService.CreateAgentMessage(ctx, roomName, agent.ID, idempotencyKey, model.MessageTypeMeetingSummary, formatTranscriptDigest(digest), extraData)
+	return err
+}
+
+// HandleLiveCaption relays a caption to the room's realtime clients without
+// storing it.
+func (s *AgentEventService) HandleLiveCaption(ctx context.Context, body []byte) (*AgentEventResult, error) {
+	var payload LiveCaptionPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAgentEvent, err)
+	}
+	if strings.TrimSpace(payload.Text) == "" {
+		return nil, fmt.Errorf("%w: text is required", ErrInvalidAgentEvent)
+	}
+
+	room, err := s.roomRepo.GetByName(ctx, payload.RoomName)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	redactor, err := s.redactionService.RedactorForRoom(ctx, room.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	caption := map[string]interface{}{
+		"speaker_identity": payload.SpeakerIdentity,
+		"speaker_name":     payload.SpeakerName,
+		"text":             redactor.Redact(payload.Text),
+		"is_final":         payload.IsFinal,
+		"timestamp":        payload.Timestamp,
+	}
+	if err := s.publisher.Publish(ctx, events.NewRoomEvent(events.LiveCaption, room.ID, caption)); err != nil {
+		return nil, err
+	}
+
+	return &AgentEventResult{}, nil
+}
+
+func (s *AgentEventService) HandleMeetingSummary(ctx context.Context, body []byte) (*AgentEventResult, error) {
+	var payload MeetingSummaryPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAgentEvent, err)
+	}
+	if strings.TrimSpace(payload.Summary) == "" {
+		return nil, fmt.Errorf("%w: summary is required", ErrInvalidAgentEvent)
+	}
+
+	// The summary is posted in the room, so it gets the room's redaction.
+	redactor, err := s.redactorForRoomName(ctx, payload.RoomName)
+	if err != nil {
+		return nil, err
+	}
+	summary := redactor.Redact(payload.Summary)
+	keyPoints := make([]string, len(payload.KeyPoints))
+	for i, point := range payload.KeyPoints {
+		keyPoints[i] = redactor.Redact(point)
+	}
+
+	sessionStart, _ := time.Parse(time.RFC3339, payload.SessionStart)
+	sessionEnd, _ := time.Parse(time.RFC3339, payload.SessionEnd)
+	extraData := &model.ExtraData{
+		Summary: &model.MeetingSummaryData{
+			Summary:      summary,
+			KeyPoints:    keyPoints,
+			SessionStart: sessionStart,
+			SessionEnd:   sessionEnd,
+			Source:       model.SummarySourceAgent,
+		},
+	}
+
+	return s.createMessage(ctx, &payload.AgentEvent, model.MessageTypeMeetingSummary, summary, extraData)
+}
+
+func (s *AgentEventService) HandleActionItems(ctx context.Context, body []byte) (*AgentEventResult, error) {
+	var payload ActionItemsPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAgentEvent, err)
+	}
+	if len(payload.Items) == 0 {
+		return nil, fmt.Errorf("%w: items are required", ErrInvalidAgentEvent)
+	}
+
+	redactor, err := s.redactorForRoomName(ctx, payload.RoomName)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]model.ActionItem, len(payload.Items))
+	for i, item := range payload.Items {
+		item.Text = redactor.Redact(item.Text)
+		item.Assignee = redactor.Redact(item.Assignee)
+		items[i] = item
+	}
+
+	var content strings.Builder
+	content.WriteString("Action items:")
+	for _, item := range items {
+		content.WriteString("\n- " + item.Text)
+		if item.Assignee != "" {
+			content.WriteString(" (" + item.Assignee + ")")
+		}
+	}
+
+	sessionStart, _ := time.Parse(time.RFC3339, payload.SessionStart)
+	extraData := &model.ExtraData{
+		ActionItems: &model.ActionItemsData{
+			Items:        items,
+			SessionStart: sessionStart,
+		},
+	}
+
+	return s.createMessage(ctx, &payload.AgentEvent, model.MessageTypeActionItems, content.String(), extraData)
+}
+
+// HandleAgentPresence handles both agent_joined and agent_left.
+func (s *AgentEventService) HandleAgentPresence(ctx context.Context, body []byte) (*AgentEventResult, error) {
+	var payload AgentEvent
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAgentEvent, err)
+	}
+
+	agent, err := s.agentService.ResolveAgent(ctx, payload.AgentIdentity, payload.AgentName)
+	if err != nil {
+		return nil, err
+	}
+
+	messageType, content := model.MessageTypeAgentJoined, agent.DisplayName+" joined the meeting."
+	if payload.Event == AgentEventAgentLeft {
+		messageType, content = model.MessageTypeAgentLeft, agent.DisplayName+" left the meeting."
+	}
+
+	extraData := &model.ExtraData{
+		Agent: &model.AgentData{
+			Identity: agent.Identity,
+			Name:     agent.DisplayName,
+		},
+	}
+
+	message, created, err := s.messageService.CreateAgentMessage(ctx, payload.RoomName, agent.ID, payload.idempotencyKey(), messageType, content, extraData)
+	if err != nil {
+		return nil, err
+	}
+	return &AgentEventResult{Message: message, Duplicate: !created}, nil
+}
+
+// redactorForRoomName returns the redactor for the policy of the room an
+// event is for.
+func (s *AgentEventService) redactorForRoomName(ctx context.Context, roomName string) (*Redactor, error) {
+	room, err := s.roomRepo.GetByName(ctx, roomName)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+	return s.redactionService.RedactorForRoom(ctx, room.ID)
+}
+
+// createMessage posts a message authored by the agent the event comes from.
+func (s *AgentEventService) createMessage(ctx context.Context, event *AgentEvent, messageType model.MessageType, content string, extraData *model.ExtraData) (*AgentEventResult, error) {
+	agent, err := s.agentService.ResolveAgent(ctx, event.AgentIdentity, event.AgentName)
+	if err != nil {
+		return nil, err
+	}
+
+	message, created, err := s.messageService.CreateAgentMessage(ctx, event.RoomName, agent.ID, event.idempotencyKey(), messageType, content, extraData)
+	if err != nil {
+		return nil, err
+	}
+	return &AgentEventResult{Message: message, Duplicate: !created}, nil
+}
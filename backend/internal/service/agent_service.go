@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+
+	"livekit-consulting/backend/internal/model"
+	"livekit-consulting/backend/internal/repository"
+)
+
+// AgentService manages the registry of bot principals that author agent
+// messages.
+type AgentService struct {
+	agentRepo repository.AgentRepository
+}
+
+func NewAgentService(agentRepo repository.AgentRepository) *AgentService {
+	return &AgentService{agentRepo: agentRepo}
+}
+
+// ResolveAgent returns the registered agent with the given identity,
+// registering it on first use. Agents that do not identify themselves are
+// treated as the default meeting assistant. A non-empty name renames the
+// agent.
+func (s *AgentService) ResolveAgent(ctx context.Context, identity, name string) (*model.Agent, error) {
+	if identity == "" {
+		identity = model.DefaultAgentIdentity
+	}
+	if name == "" {
+		agent, err := s.agentRepo.GetByIdentity(ctx, identity)
+		if err != nil || agent != nil {
+			return agent, err
+		}
+	}
+	return s.agentRepo.Upsert(ctx, identity, name)
+}
+
+func (s *AgentService) ListAgents(ctx context.Context) ([]*model.Agent, error) {
+	return s.agentRepo.List(ctx)
+}
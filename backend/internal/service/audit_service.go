@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"livekit-consulting/backend/internal/model"
+	"livekit-consulting/backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const maxAuditLogPage = 200
+
+// AuditActor identifies who performed an audited action and from where.
+type AuditActor struct {
+	UserID    uuid.UUID
+	IPAddress string
+	UserAgent string
+}
+
+// AuditService records sensitive actions taken in rooms and lets room owners
+// review them.
+type AuditService struct {
+	auditRepo repository.AuditLogRepository
+	roomRepo  repository.RoomRepository
+}
+
+func NewAuditService(auditRepo repository.AuditLogRepository, roomRepo repository.RoomRepository) *AuditService {
+	return &AuditService{
+		auditRepo: auditRepo,
+		roomRepo:  roomRepo,
+	}
+}
+
+// Record writes an audit log entry. Callers performing the action only after
+// the entry is written get an audit trail that cannot be skipped.
+func (s *AuditService) Record(ctx context.Context, roomID uuid.UUID, actor AuditActor, action, targetType, targetID string, details model.Metadata) error {
+	entry := &model.AuditLog{
+		RoomID:  &roomID,
+		ActorID: &actor.UserID,
+		Action:  action,
+		Details: details,
+	}
+	if targetType != "" {
+		entry.TargetType = &targetType
+	}
+	if targetID != "" {
+		entry.TargetID = &targetID
+	}
+	if actor.IPAddress != "" {
+		entry.IPAddress = &actor.IPAddress
+	}
+	if actor.UserAgent != "" {
+		entry.UserAgent = &actor.UserAgent
+	}
+	return s.auditRepo.Create(ctx, entry)
+}
+
+// GetRoomAuditLog returns a page of the room's audit log, newest first. Only
+// the room owner may read it.
+func (s *AuditService) GetRoomAuditLog(ctx context.Context, roomID, userID uuid.UUID, limit int, before *time.Time) ([]*model.AuditLog, error) {
+	room, err := s.roomRepo.GetByID(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+	if room.OwnerID != userID {
+		return nil, ErrPermissionDenied
+	}
+
+	if limit <= 0 || limit > maxAuditLogPage {
+		limit = maxAuditLogPage
+	}
+	return s.auditRepo.GetByRoomID(ctx, roomID, limit, before)
+}
@@ -81,8 +81,7 @@ func (s *AuthService) SignIn(ctx context.Context, req *model.UserSignInRequest)
     livekitToken, err := s.livekitService.GenerateToken(
         user.ID.String(),
         "default",
-        true,      
-        true,      
+        TokenOptions{Role: model.RoleParticipant, Name: user.Name},
     )
     if err != nil {
         return nil, err
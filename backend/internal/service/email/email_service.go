@@ -2,6 +2,7 @@ package email
 
 import (
     "context"
+    "html"
 )
 
 // EmailProvider is the interface that all email providers must implement
@@ -59,3 +60,20 @@ func (s *EmailService) SendRoomInviteEmail(ctx context.Context, to, roomName, in
     
     return s.provider.SendEmail(ctx, to, subject, htmlContent, textContent)
 }
+
+func (s *EmailService) SendMentionEmail(ctx context.Context, to, authorName, roomName, excerpt, roomURL string) error {
+    subject := authorName + " mentioned you in " + roomName
+    htmlContent := `
+        <html>
+        <body>
+            <h2>You were mentioned</h2>
+            <p><strong>` + html.EscapeString(authorName) + `</strong> mentioned you in <strong>` + html.EscapeString(roomName) + `</strong>:</p>
+            <blockquote>` + html.EscapeString(excerpt) + `</blockquote>
+            <a href="` + html.EscapeString(roomURL) + `">Open Room</a>
+        </body>
+        </html>
+    `
+    textContent := authorName + " mentioned you in " + roomName + ": " + excerpt + "\n\n" + roomURL
+
+    return s.provider.SendEmail(ctx, to, subject, htmlContent, textContent)
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"livekit-consulting/backend/internal/config"
+
+	"cloud.google.com/go/storage"
+)
+
+// gcsTranscriptStorage reads transcripts from Google Cloud Storage through
+// the attachment storage's client.
+type gcsTranscriptStorage struct {
+	client *storage.Client
+	bucket string
+}
+
+func NewGCSTranscriptStorage(cfg *config.Config, fileStorage FileStorage) (TranscriptStorage, error) {
+	files, ok := fileStorage.(*GCSFileStorage)
+	if !ok {
+		return nil, fmt.Errorf("gcs transcript storage requires STORAGE_PROVIDER=gcs")
+	}
+
+	return &gcsTranscriptStorage{
+		client: files.client,
+		bucket: transcriptBucket(cfg, files.bucket),
+	}, nil
+}
+
+func (s *gcsTranscriptStorage) GetTranscriptFile(ctx context.Context, key string) (io.ReadCloser, error) {
+	reader, err := s.client.Bucket(s.bucket).Object(key).NewReader(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, ErrTranscriptFileNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return reader, nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"time"
 
+	"livekit-consulting/backend/internal/model"
+
 	"github.com/livekit/protocol/auth"
 	"github.com/livekit/protocol/livekit"
 	lksdk "github.com/livekit/server-sdk-go/v2"
@@ -13,30 +15,72 @@ type LiveKitService struct {
 	apiKey    string
 	apiSecret string
 	url       string
+	tokenTTL  time.Duration
 }
 
-func NewLiveKitService(apiKey, apiSecret, url string) *LiveKitService {
+func NewLiveKitService(apiKey, apiSecret, url string, tokenTTL time.Duration) *LiveKitService {
 	return &LiveKitService{
 		apiKey:    apiKey,
 		apiSecret: apiSecret,
 		url:       url,
+		tokenTTL:  tokenTTL,
 	}
 }
 
-// GenerateToken creates a LiveKit access token for a participant
-func (s *LiveKitService) GenerateToken(identity, roomName string, canPublish, canSubscribe bool) (string, error) {
-	at := auth.NewAccessToken(s.apiKey, s.apiSecret)
-	grant := &auth.VideoGrant{
-		RoomJoin:     true,
-		Room:         roomName,
-		CanPublish:   &canPublish,
-		CanSubscribe: &canSubscribe,
+// TokenOptions describe the participant a LiveKit token is issued to
+type TokenOptions struct {
+	// Role is the participant's room role, which decides their grants
+	Role     string
+	Name     string
+	Metadata string
+	// TTL is how long the token stays valid; zero uses the server default
+	TTL time.Duration
+}
+
+// GenerateToken creates a LiveKit access token for a participant, with the
+// grants of their role
+func (s *LiveKitService) GenerateToken(identity, roomName string, opts TokenOptions) (string, error) {
+	ttl := opts.TTL
+	if ttl <= 0 {
+		ttl = s.tokenTTL
 	}
-	at.SetVideoGrant(grant).SetIdentity(identity).SetValidFor(24 * time.Hour)
+
+	at := auth.NewAccessToken(s.apiKey, s.apiSecret)
+	at.SetVideoGrant(videoGrantForRole(roomName, opts.Role)).
+		SetIdentity(identity).
+		SetName(opts.Name).
+		SetMetadata(opts.Metadata).
+		SetValidFor(ttl)
 
 	return at.ToJWT()
 }
 
+// videoGrantForRole returns the grants of a room role: owners and moderators
+// administer the room, participants publish and subscribe, and viewers, like
+// any role we do not know, only subscribe.
+func videoGrantForRole(roomName, role string) *auth.VideoGrant {
+	grant := &auth.VideoGrant{
+		RoomJoin: true,
+		Room:     roomName,
+	}
+	switch role {
+	case model.RoleOwner, model.RoleModerator:
+		grant.RoomAdmin = true
+		grant.SetCanPublish(true)
+		grant.SetCanPublishData(true)
+		grant.SetCanSubscribe(true)
+	case model.RoleParticipant:
+		grant.SetCanPublish(true)
+		grant.SetCanPublishData(true)
+		grant.SetCanSubscribe(true)
+	default:
+		grant.SetCanPublish(false)
+		grant.SetCanPublishData(false)
+		grant.SetCanSubscribe(true)
+	}
+	return grant
+}
+
 // CreateRoom creates a new room in LiveKit
 func (s *LiveKitService) CreateRoom(ctx context.Context, roomName string) (*livekit.Room, error) {
 	roomClient := lksdk.NewRoomServiceClient(s.url, s.apiKey, s.apiSecret)
@@ -75,3 +119,55 @@ func (s *LiveKitService) DeleteRoom(ctx context.Context, roomName string) error
 
 	return err
 }
+
+// GetParticipant returns a participant currently in a room
+func (s *LiveKitService) GetParticipant(ctx context.Context, roomName, identity string) (*livekit.ParticipantInfo, error) {
+	roomClient := lksdk.NewRoomServiceClient(s.url, s.apiKey, s.apiSecret)
+
+	return roomClient.GetParticipant(ctx, &livekit.RoomParticipantIdentity{
+		Room:     roomName,
+		Identity: identity,
+	})
+}
+
+// MutePublishedTrack mutes or unmutes a track a participant is publishing
+func (s *LiveKitService) MutePublishedTrack(ctx context.Context, roomName, identity, trackSID string, muted bool) (*livekit.TrackInfo, error) {
+	roomClient := lksdk.NewRoomServiceClient(s.url, s.apiKey, s.apiSecret)
+
+	res, err := roomClient.MutePublishedTrack(ctx, &livekit.MuteRoomTrackRequest{
+		Room:     roomName,
+		Identity: identity,
+		TrackSid: trackSID,
+		Muted:    muted,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Track, nil
+}
+
+// RemoveParticipant disconnects a participant from a room
+func (s *LiveKitService) RemoveParticipant(ctx context.Context, roomName, identity string) error {
+	roomClient := lksdk.NewRoomServiceClient(s.url, s.apiKey, s.apiSecret)
+
+	_, err := roomClient.RemoveParticipant(ctx, &livekit.RoomParticipantIdentity{
+		Room:     roomName,
+		Identity: identity,
+	})
+
+	return err
+}
+
+// UpdateParticipantPermission replaces the permissions of a participant in a
+// room; they apply until the participant reconnects
+func (s *LiveKitService) UpdateParticipantPermission(ctx context.Context, roomName, identity string, permission *livekit.ParticipantPermission) (*livekit.ParticipantInfo, error) {
+	roomClient := lksdk.NewRoomServiceClient(s.url, s.apiKey, s.apiSecret)
+
+	return roomClient.UpdateParticipant(ctx, &livekit.UpdateParticipantRequest{
+		Room:       roomName,
+		Identity:   identity,
+		Permission: permission,
+	})
+}
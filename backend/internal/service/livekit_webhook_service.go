@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"livekit-consulting/backend/internal/events"
+	"livekit-consulting/backend/internal/model"
+	"livekit-consulting/backend/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/webhook"
+	"github.com/rs/zerolog/log"
+)
+
+// LiveKitWebhookService keeps our rooms in step with what LiveKit reports
+// about their calls.
+type LiveKitWebhookService struct {
+	roomRepo        repository.RoomRepository
+	participantRepo repository.ParticipantRepository
+	sessionRepo     repository.MeetingSessionRepository
+	messageService  *MessageService
+	publisher       events.Publisher
+}
+
+func NewLiveKitWebhookService(
+	roomRepo repository.RoomRepository,
+	participantRepo repository.ParticipantRepository,
+	sessionRepo repository.MeetingSessionRepository,
+	messageService *MessageService,
+	publisher events.Publisher,
+) *LiveKitWebhookService {
+	return &LiveKitWebhookService{
+		roomRepo:        roomRepo,
+		participantRepo: participantRepo,
+		sessionRepo:     sessionRepo,
+		messageService:  messageService,
+		publisher:       publisher,
+	}
+}
+
+// HandleEvent applies a verified LiveKit webhook event. Events for rooms we
+// do not know and event types we do not use are ignored, so LiveKit does not
+// keep retrying them.
+func (s *LiveKitWebhookService) HandleEvent(ctx context.Context, event *livekit.WebhookEvent) error {
+	roomName := event.GetRoom().GetName()
+	if roomName == "" {
+		roomName = event.GetEgressInfo().GetRoomName()
+	}
+	if roomName == "" {
+		return nil
+	}
+
+	room, err := s.roomRepo.GetByName(ctx, roomName)
+	if err != nil {
+		return err
+	}
+	if room == nil {
+		log.Debug().
+			Str("event", event.GetEvent()).
+			Str("livekit_room", roomName).
+			Msg("Ignoring LiveKit webhook for unknown room")
+		return nil
+	}
+
+	switch event.GetEvent() {
+	case webhook.EventRoomStarted:
+		return s.handleRoomStarted(ctx, room, event)
+	case webhook.EventRoomFinished:
+		return s.handleRoomFinished(ctx, room, event)
+	case webhook.EventParticipantJoined:
+		return s.handleParticipantJoined(ctx, room, event)
+	case webhook.EventParticipantLeft:
+		return s.handleParticipantLeft(ctx, room, event)
+	case webhook.EventEgressEnded:
+		return s.handleEgressEnded(ctx, room, event)
+	}
+	return nil
+}
+
+func (s *LiveKitWebhookService) handleRoomStarted(ctx context.Context, room *model.Room, event *livekit.WebhookEvent) error {
+	sid := event.GetRoom().GetSid()
+	if sid == "" {
+		return nil
+	}
+	if err := s.roomRepo.UpdateSID(ctx, room.ID, sid); err != nil {
+		return err
+	}
+
+	session, err := s.startSession(ctx, room, event)
+	if err != nil {
+		return err
+	}
+	return s.publish(ctx, events.MeetingStarted, room, session)
+}
+
+func (s *LiveKitWebhookService) handleRoomFinished(ctx context.Context, room *model.Room, event *livekit.WebhookEvent) error {
+	sid := event.GetRoom().GetSid()
+	if sid == "" {
+		return nil
+	}
+	if err := s.sessionRepo.End(ctx, sid, webhookEventTime(event)); err != nil {
+		return err
+	}
+
+	session, err := s.sessionRepo.GetBySID(ctx, sid)
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		return nil
+	}
+	return s.publish(ctx, events.MeetingFinished, room, session)
+}
+
+// startSession records the session of the event's call, or loads it when
+// room_started was already handled.
+func (s *LiveKitWebhookService) startSession(ctx context.Context, room *model.Room, event *livekit.WebhookEvent) (*model.MeetingSession, error) {
+	startedAt := webhookEventTime(event)
+	if created := event.GetRoom().GetCreationTime(); created > 0 {
+		startedAt = time.Unix(created, 0)
+	}
+
+	session := &model.MeetingSession{
+		RoomID:    room.ID,
+		RoomSID:   event.GetRoom().GetSid(),
+		StartedAt: startedAt,
+	}
+	if err := s.sessionRepo.Start(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// handleParticipantJoined records that a room participant joined the call,
+// adds them to the session's attendance and posts a participant_joined
+// message. Agents and egress workers are not people and are skipped.
+func (s *LiveKitWebhookService) handleParticipantJoined(ctx context.Context, room *model.Room, event *livekit.WebhookEvent) error {
+	info := event.GetParticipant()
+	if info == nil || !isHumanParticipant(info) {
+		return nil
+	}
+
+	participant, err := s.participantRepo.GetByRoomAndIdentity(ctx, room.ID, info.GetIdentity())
+	if err != nil {
+		return err
+	}
+
+	joinedAt := webhookEventTime(event)
+	if info.GetJoinedAt() > 0 {
+		joinedAt = time.Unix(info.GetJoinedAt(), 0)
+	}
+
+	data := &model.ParticipantData{
+		Identity: info.GetIdentity(),
+		Name:     info.GetName(),
+	}
+	var authorID *uuid.UUID
+	if participant != nil {
+		authorID = participant.UserID
+		data.ParticipantID = &participant.ID
+		if data.Name == "" {
+			data.Name = participant.Name
+		}
+		if err := s.participantRepo.MarkJoined(ctx, participant.ID, info.GetIdentity(), joinedAt); err != nil {
+			return err
+		}
+	}
+	if data.Name == "" {
+		data.Name = data.Identity
+	}
+
+	// Participant events can reach us before room_started does.
+	if event.GetRoom().GetSid() != "" {
+		session, err := s.startSession(ctx, room, event)
+		if err != nil {
+			return err
+		}
+		err = s.sessionRepo.RecordJoin(ctx, &model.MeetingAttendance{
+			SessionID:     session.ID,
+			ParticipantID: data.ParticipantID,
+			Identity:      data.Identity,
+			Name:          data.Name,
+			JoinedAt:      joinedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	idempotencyKey := ""
+	if event.GetId() != "" {
+		idempotencyKey = "livekit:" + event.GetId()
+	}
+	_, created, err := s.messageService.CreateSystemMessage(ctx, *room.LiveKitRoomName, authorID, idempotencyKey,
+		model.MessageTypeParticipantJoined, data.Name+" joined the meeting.", &model.ExtraData{Participant: data})
+	if err != nil || !created {
+		// A retried delivery was published the first time round.
+		return err
+	}
+
+	return s.publish(ctx, events.MeetingParticipantJoined, room, data)
+}
+
+func (s *LiveKitWebhookService) handleParticipantLeft(ctx context.Context, room *model.Room, event *livekit.WebhookEvent) error {
+	info := event.GetParticipant()
+	if info == nil || !isHumanParticipant(info) {
+		return nil
+	}
+
+	if sid := event.GetRoom().GetSid(); sid != "" {
+		session, err := s.sessionRepo.GetBySID(ctx, sid)
+		if err != nil {
+			return err
+		}
+		if session != nil {
+			if err := s.sessionRepo.RecordLeave(ctx, session.ID, info.GetIdentity(), webhookEventTime(event)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return s.publish(ctx, events.MeetingParticipantLeft, room, map[string]interface{}{
+		"identity": info.GetIdentity(),
+		"name":     info.GetName(),
+	})
+}
+
+func (s *LiveKitWebhookService) handleEgressEnded(ctx context.Context, room *model.Room, event *livekit.WebhookEvent) error {
+	egress := event.GetEgressInfo()
+	if egress == nil {
+		return nil
+	}
+
+	files := make([]map[string]interface{}, 0, len(egress.GetFileResults()))
+	for _, file := range egress.GetFileResults() {
+		files = append(files, map[string]interface{}{
+			"filename": file.GetFilename(),
+			"location": file.GetLocation(),
+			"size":     file.GetSize(),
+			"duration": file.GetDuration(),
+		})
+	}
+
+	if egress.GetError() != "" {
+		log.Warn().
+			Str("room_id", room.ID.String()).
+			Str("egress_id", egress.GetEgressId()).
+			Str("error", egress.GetError()).
+			Msg("LiveKit egress ended with an error")
+	}
+
+	return s.publish(ctx, events.MeetingEgressEnded, room, map[string]interface{}{
+		"egress_id": egress.GetEgressId(),
+		"status":    egress.GetStatus().String(),
+		"error":     egress.GetError(),
+		"files":     files,
+	})
+}
+
+func (s *LiveKitWebhookService) publish(ctx context.Context, eventType events.Type, room *model.Room, data interface{}) error {
+	return s.publisher.Publish(ctx, events.NewRoomEvent(eventType, room.ID, data))
+}
+
+// webhookEventTime is when LiveKit says the event happened.
+func webhookEventTime(event *livekit.WebhookEvent) time.Time {
+	if event.GetCreatedAt() > 0 {
+		return time.Unix(event.GetCreatedAt(), 0)
+	}
+	return time.Now()
+}
+
+func isHumanParticipant(info *livekit.ParticipantInfo) bool {
+	switch info.GetKind() {
+	case livekit.ParticipantInfo_AGENT, livekit.ParticipantInfo_EGRESS:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"livekit-consulting/backend/internal/events"
+	"livekit-consulting/backend/internal/model"
+	"livekit-consulting/backend/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// ErrInvalidInvite is returned when a join token is unknown, expired or
+	// for another room.
+	ErrInvalidInvite = errors.New("invalid or expired invite")
+	// ErrLobbyRequestNotFound is returned when a lobby request does not exist
+	// in the room.
+	ErrLobbyRequestNotFound = errors.New("lobby request not found")
+	// ErrLobbyRequestDecided is returned when a lobby request was already
+	// approved or denied.
+	ErrLobbyRequestDecided = errors.New("lobby request already decided")
+)
+
+// LobbyService holds guests joining through an invite in the room's lobby
+// until one of its hosts admits them. Only admitted guests get a LiveKit
+// token.
+type LobbyService struct {
+	lobbyRepo          repository.LobbyRequestRepository
+	inviteRepo         repository.InviteRepository
+	participantRepo    repository.ParticipantRepository
+	participantService *ParticipantService
+	auditService       *AuditService
+	publisher          events.Publisher
+}
+
+func NewLobbyService(
+	lobbyRepo repository.LobbyRequestRepository,
+	inviteRepo repository.InviteRepository,
+	participantRepo repository.ParticipantRepository,
+	participantService *ParticipantService,
+	auditService *AuditService,
+	publisher events.Publisher,
+) *LobbyService {
+	return &LobbyService{
+		lobbyRepo:          lobbyRepo,
+		inviteRepo:         inviteRepo,
+		participantRepo:    participantRepo,
+		participantService: participantService,
+		auditService:       auditService,
+		publisher:          publisher,
+	}
+}
+
+// JoinExternal handles a guest joining with an invite token. The first call
+// puts them in the lobby and notifies the hosts; guests call again to learn
+// whether they were admitted, and get their token once they are. Hosts
+// joining through an invite to their own account skip the lobby.
+func (s *LobbyService) JoinExternal(ctx context.Context, roomID uuid.UUID, inviteToken string) (*model.JoinRoomResponse, error) {
+	invite, err := s.inviteRepo.GetByToken(ctx, inviteToken)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidInvite
+		}
+		return nil, err
+	}
+	if invite.RoomID != roomID || time.Now().After(invite.ExpiresAt) {
+		return nil, ErrInvalidInvite
+	}
+
+	participant, err := s.participantRepo.GetByRoomAndEmail(ctx, roomID, invite.InviteeEmail)
+	if err != nil {
+		return nil, err
+	}
+	if isHost(participant) {
+		return s.admit(ctx, roomID, inviteToken, nil)
+	}
+
+	request, err := s.lobbyRepo.GetByInviteID(ctx, invite.ID)
+	if err != nil {
+		return nil, err
+	}
+	if request == nil {
+		request = &model.LobbyRequest{
+			RoomID:   roomID,
+			InviteID: invite.ID,
+			Email:    invite.InviteeEmail,
+			Name:     invite.InviteeName,
+		}
+		if participant != nil {
+			request.ParticipantID = &participant.ID
+			request.Name = participant.Name
+		}
+		created, err := s.lobbyRepo.Create(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			s.publish(ctx, events.NewRoomEvent(events.LobbyRequested, roomID, request))
+		} else if request, err = s.lobbyRepo.GetByInviteID(ctx, invite.ID); err != nil {
+			// A concurrent join created it first.
+			return nil, err
+		}
+	}
+
+	switch request.Status {
+	case model.LobbyStatusApproved:
+		return s.admit(ctx, roomID, inviteToken, &request.ID)
+	default:
+		return &model.JoinRoomResponse{Status: request.Status, RequestID: &request.ID}, nil
+	}
+}
+
+func (s *LobbyService) admit(ctx context.Context, roomID uuid.UUID, inviteToken string, requestID *uuid.UUID) (*model.JoinRoomResponse, error) {
+	token, err := s.participantService.GenerateParticipantToken(ctx, roomID, inviteToken)
+	if err != nil {
+		return nil, err
+	}
+	return &model.JoinRoomResponse{
+		Status:       model.LobbyStatusApproved,
+		RequestID:    requestID,
+		LiveKitToken: token,
+	}, nil
+}
+
+// ListPending returns the guests waiting in the room's lobby to one of its
+// hosts.
+func (s *LobbyService) ListPending(ctx context.Context, roomID, userID uuid.UUID) ([]*model.LobbyRequest, error) {
+	if err := s.requireHost(ctx, roomID, userID); err != nil {
+		return nil, err
+	}
+	return s.lobbyRepo.GetPendingByRoomID(ctx, roomID)
+}
+
+// Approve admits a waiting guest to the call.
+func (s *LobbyService) Approve(ctx context.Context, roomID, requestID uuid.UUID, actor AuditActor) (*model.LobbyRequest, error) {
+	return s.decide(ctx, roomID, requestID, actor, model.LobbyStatusApproved)
+}
+
+// Deny turns a waiting guest away. Their invite cannot be used to wait in
+// the lobby again.
+func (s *LobbyService) Deny(ctx context.Context, roomID, requestID uuid.UUID, actor AuditActor) (*model.LobbyRequest, error) {
+	return s.decide(ctx, roomID, requestID, actor, model.LobbyStatusDenied)
+}
+
+func (s *LobbyService) decide(ctx context.Context, roomID, requestID uuid.UUID, actor AuditActor, status string) (*model.LobbyRequest, error) {
+	if err := s.requireHost(ctx, roomID, actor.UserID); err != nil {
+		return nil, err
+	}
+
+	request, err := s.lobbyRepo.GetByID(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request == nil || request.RoomID != roomID {
+		return nil, ErrLobbyRequestNotFound
+	}
+	if request.Status != model.LobbyStatusPending {
+		return nil, ErrLobbyRequestDecided
+	}
+
+	decided, err := s.lobbyRepo.Decide(ctx, requestID, status, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if decided == nil {
+		// Another host decided it in the meantime.
+		return nil, ErrLobbyRequestDecided
+	}
+
+	action, eventType := model.AuditActionLobbyApproved, events.LobbyApproved
+	if status == model.LobbyStatusDenied {
+		action, eventType = model.AuditActionLobbyDenied, events.LobbyDenied
+	}
+	details := model.Metadata{"email": decided.Email, "name": decided.Name}
+	if err := s.auditService.Record(ctx, roomID, actor, action, "lobby_request", requestID.String(), details); err != nil {
+		return nil, err
+	}
+
+	s.publish(ctx, events.NewRoomEvent(eventType, roomID, decided))
+	return decided, nil
+}
+
+// publish notifies the hosts. The lobby state is already saved, and guests
+// and hosts can always reload it, so a failure is only logged.
+func (s *LobbyService) publish(ctx context.Context, event events.Event) {
+	if err := s.publisher.Publish(ctx, event); err != nil {
+		log.Error().
+			Err(err).
+			Str("room_id", event.RoomID.String()).
+			Str("event", string(event.Type)).
+			Msg("Failed to publish lobby event")
+	}
+}
+
+// isHost reports whether the invitee is an owner or moderator of the room
+// with an account of their own. Invite rows created only for an email are
+// never hosts, whatever role they were given.
+func isHost(participant *model.RoomParticipant) bool {
+	return participant != nil && participant.UserID != nil && participant.CanModerate()
+}
+
+func (s *LobbyService) requireHost(ctx context.Context, roomID, userID uuid.UUID) error {
+	participant, err := s.participantRepo.GetByRoomAndUserID(ctx, roomID, userID)
+	if err != nil {
+		return err
+	}
+	if participant == nil || !participant.IsActive {
+		return ErrNotRoomMember
+	}
+	if !participant.CanModerate() {
+		return ErrPermissionDenied
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"livekit-consulting/backend/internal/config"
+)
+
+// localTranscriptStorage reads transcripts from a directory, laid out like
+// the bucket the agent would otherwise upload to. Meant for development.
+type localTranscriptStorage struct {
+	root string
+}
+
+func NewLocalTranscriptStorage(cfg *config.Config) (TranscriptStorage, error) {
+	root, err := filepath.Abs(cfg.TranscriptStorageLocalDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &localTranscriptStorage{root: root}, nil
+}
+
+func (s *localTranscriptStorage) GetTranscriptFile(ctx context.Context, key string) (io.ReadCloser, error) {
+	// Cleaning the key as an absolute path drops any ".." that would climb
+	// out of the root.
+	name := filepath.Join(s.root, filepath.FromSlash(path.Clean("/"+key)))
+	file, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrTranscriptFileNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
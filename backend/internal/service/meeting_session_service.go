@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"livekit-consulting/backend/internal/model"
+	"livekit-consulting/backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const maxMeetingSessionPage = 100
+
+// ErrMeetingSessionNotFound is returned when a meeting session does not
+// exist in the room.
+var ErrMeetingSessionNotFound = errors.New("meeting session not found")
+
+// MeetingSessionService serves the history of the calls held in a room and
+// who attended them.
+type MeetingSessionService struct {
+	sessionRepo     repository.MeetingSessionRepository
+	participantRepo repository.ParticipantRepository
+}
+
+func NewMeetingSessionService(sessionRepo repository.MeetingSessionRepository, participantRepo repository.ParticipantRepository) *MeetingSessionService {
+	return &MeetingSessionService{
+		sessionRepo:     sessionRepo,
+		participantRepo: participantRepo,
+	}
+}
+
+// ListSessions returns a page of the room's sessions, latest first.
+func (s *MeetingSessionService) ListSessions(ctx context.Context, roomID, userID uuid.UUID, limit int, before *time.Time) ([]*model.MeetingSession, error) {
+	if err := s.requireMember(ctx, roomID, userID); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 || limit > maxMeetingSessionPage {
+		limit = maxMeetingSessionPage
+	}
+	sessions, err := s.sessionRepo.GetByRoomID(ctx, roomID, limit, before)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, session := range sessions {
+		session.DurationMinutes = minutesBetween(session.StartedAt, session.EndedAt, now)
+	}
+	return sessions, nil
+}
+
+// GetSession returns one of the room's sessions with its attendance.
+func (s *MeetingSessionService) GetSession(ctx context.Context, roomID, sessionID, userID uuid.UUID) (*model.MeetingSession, error) {
+	if err := s.requireMember(ctx, roomID, userID); err != nil {
+		return nil, err
+	}
+
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || session.RoomID != roomID {
+		return nil, ErrMeetingSessionNotFound
+	}
+
+	attendance, err := s.sessionRepo.GetAttendance(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if session.EndedAt != nil {
+		now = *session.EndedAt
+	}
+	session.DurationMinutes = minutesBetween(session.StartedAt, session.EndedAt, now)
+	session.Attendance = summarizeAttendance(attendance, now)
+	return session, nil
+}
+
+// LinkTranscript links a transcript message to the session it was recorded
+// in: the latest one of its room started by the time the transcript began.
+func (s *MeetingSessionService) LinkTranscript(ctx context.Context, message *model.Message) (*model.MeetingSession, error) {
+	at := message.CreatedAt
+	if extra := message.ExtraData; extra != nil && extra.Transcript != nil && !extra.Transcript.SessionStart.IsZero() {
+		at = extra.Transcript.SessionStart
+	}
+	return s.sessionRepo.LinkTranscript(ctx, message.RoomID, message.ID, at)
+}
+
+func (s *MeetingSessionService) requireMember(ctx context.Context, roomID, userID uuid.UUID) error {
+	isMember, err := s.participantRepo.UserHasAccess(ctx, roomID, userID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return ErrNotRoomMember
+	}
+	return nil
+}
+
+// summarizeAttendance groups stays in the call by LiveKit identity, in the
+// order people first joined, and totals the minutes of each. Stays still
+// open count up to now.
+func summarizeAttendance(attendance []*model.MeetingAttendance, now time.Time) []*model.MeetingAttendee {
+	var attendees []*model.MeetingAttendee
+	index := make(map[string]*model.MeetingAttendee)
+	total := make(map[string]time.Duration)
+	for _, stay := range attendance {
+		attendee, ok := index[stay.Identity]
+		if !ok {
+			attendee = &model.MeetingAttendee{
+				Identity: stay.Identity,
+				Name:     stay.Name,
+			}
+			index[stay.Identity] = attendee
+			attendees = append(attendees, attendee)
+		}
+		if attendee.ParticipantID == nil {
+			attendee.ParticipantID = stay.ParticipantID
+		}
+
+		attendee.Intervals = append(attendee.Intervals, model.AttendanceInterval{
+			JoinedAt: stay.JoinedAt,
+			LeftAt:   stay.LeftAt,
+		})
+		total[stay.Identity] += stayDuration(stay.JoinedAt, stay.LeftAt, now)
+	}
+
+	for _, attendee := range attendees {
+		attendee.TotalMinutes = durationMinutes(total[attendee.Identity])
+	}
+	return attendees
+}
+
+// minutesBetween returns the minutes from start to end, or to now when end
+// is not set.
+func minutesBetween(start time.Time, end *time.Time, now time.Time) float64 {
+	return durationMinutes(stayDuration(start, end, now))
+}
+
+func stayDuration(start time.Time, end *time.Time, now time.Time) time.Duration {
+	stop := now
+	if end != nil {
+		stop = *end
+	}
+	return max(stop.Sub(start), 0)
+}
+
+// durationMinutes converts d to minutes, rounded to a tenth of a minute.
+func durationMinutes(d time.Duration) float64 {
+	return math.Round(d.Minutes()*10) / 10
+}
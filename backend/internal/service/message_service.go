@@ -2,11 +2,14 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"time"
 
 	"livekit-consulting/backend/internal/model"
 	"livekit-consulting/backend/internal/repository"
+	"livekit-consulting/backend/internal/service/email"
+	"livekit-consulting/backend/internal/utils"
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
@@ -34,11 +37,63 @@ type AgentWebhookPayload struct {
 	Timestamp string `json:"timestamp"`
 }
 
+// IdempotencyKey identifies a transcript delivery: the agent uploads every
+// session's transcript to its own S3 key, so retries share the key.
+func (p *AgentWebhookPayload) IdempotencyKey() string {
+	if p.S3Keys.JSON == "" {
+		return ""
+	}
+	return "transcript:" + p.RoomName + ":" + p.S3Keys.JSON
+}
+
+const (
+	// presenceWindow is how recently a participant must have had a realtime
+	// connection open to count as online. Connections refresh it on every
+	// keep-alive, which is well inside this window.
+	presenceWindow = 2 * time.Minute
+	// mentionExcerptLength caps how much of a message is quoted in a mention
+	// email.
+	mentionExcerptLength = 280
+	// maxMentionsPage caps how many mentions one request may load.
+	maxMentionsPage = 100
+	// mentionQueueSize is how many messages with mentions may wait for their
+	// emails. Mentions beyond it are not emailed.
+	mentionQueueSize = 256
+	// mentionWorkers is how many messages have their mention emails sent at
+	// once.
+	mentionWorkers = 4
+	// mentionEmailTimeout bounds how long sending one mention email may take.
+	mentionEmailTimeout = 30 * time.Second
+)
+
+// mentionNotification is a message whose mentioned participants are waiting
+// to be emailed.
+type mentionNotification struct {
+	room      *model.Room
+	message   *model.Message
+	mentioned []*model.RoomParticipant
+}
+
+var (
+	// ErrNotRoomMember is returned when a user acts on a room they do not belong to.
+	ErrNotRoomMember = errors.New("user is not a member of this room")
+	// ErrMessageNotFound is returned when a message does not exist in the room.
+	ErrMessageNotFound = errors.New("message not found")
+	// ErrRoomNotFound is returned when a room referenced by name or ID does not exist.
+	ErrRoomNotFound = errors.New("room not found")
+	// ErrPermissionDenied is returned when a room member lacks the role an action requires.
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
 type MessageService struct {
 	messageRepo     repository.MessageRepository
 	participantRepo repository.ParticipantRepository
 	attachmentRepo  repository.AttachmentRepository
 	roomRepo        repository.RoomRepository
+	reactionRepo    repository.ReactionRepository
+	emailService    *email.EmailService
+	frontendURL     string
+	mentions        chan mentionNotification
 }
 
 func NewMessageService(
@@ -46,13 +101,24 @@ func NewMessageService(
 	participantRepo repository.ParticipantRepository,
 	attachmentRepo repository.AttachmentRepository,
 	roomRepo repository.RoomRepository,
+	reactionRepo repository.ReactionRepository,
+	emailService *email.EmailService,
+	frontendURL string,
 ) *MessageService {
-	return &MessageService{
+	s := &MessageService{
 		messageRepo:     messageRepo,
 		participantRepo: participantRepo,
 		attachmentRepo:  attachmentRepo,
 		roomRepo:        roomRepo,
+		reactionRepo:    reactionRepo,
+		emailService:    emailService,
+		frontendURL:     frontendURL,
+		mentions:        make(chan mentionNotification, mentionQueueSize),
+	}
+	for i := 0; i < mentionWorkers; i++ {
+		go s.sendMentionEmails()
 	}
+	return s
 }
 
 func (s *MessageService) CreateMessage(ctx context.Context, req *model.CreateMessageRequest, roomID, userID uuid.UUID) (*model.Message, error) {
@@ -61,7 +127,7 @@ func (s *MessageService) CreateMessage(ctx context.Context, req *model.CreateMes
 		return nil, err
 	}
 	if !isMember {
-		return nil, errors.New("user is not a member of this room")
+		return nil, ErrNotRoomMember
 	}
 
 	message := &model.Message{
@@ -70,92 +136,258 @@ func (s *MessageService) CreateMessage(ctx context.Context, req *model.CreateMes
 		Content:     req.Content,
 		MessageType: model.MessageTypeUserMessage,
 	}
+	if req.ParentMessageID != nil {
+		root, err := s.getThreadRoot(ctx, roomID, *req.ParentMessageID)
+		if err != nil {
+			return nil, err
+		}
+		message.ParentMessageID = &root.ID
+	}
+	for _, attachmentID := range req.AttachmentIDs {
+		message.Attachments = append(message.Attachments, model.Attachment{ID: attachmentID})
+	}
 
-	_, err = s.messageRepo.Create(ctx, message)
+	mentioned, err := s.resolveMentions(ctx, roomID, req.Content)
 	if err != nil {
 		return nil, err
 	}
-
-	for _, attachmentID := range req.AttachmentIDs {
-		err := s.attachmentRepo.SetMessageID(ctx, attachmentID, message.ID)
-		if err != nil {
-			// Log error but don't fail the whole process
+	if len(mentioned) > 0 {
+		message.Metadata = &model.MessageMetadata{}
+		for _, participant := range mentioned {
+			message.Metadata.Mentions = append(message.Metadata.Mentions, participant.UserID.String())
 		}
 	}
 
+	room, err := s.messageRepo.Create(ctx, message)
+	if err != nil {
+		return nil, err
+	}
+
 	fullMessage, err := s.messageRepo.GetMessageWithAttachments(ctx, message.ID)
 	if err != nil {
 		return nil, err
 	}
 
+	if len(mentioned) > 0 {
+		s.queueMentions(room, fullMessage, mentioned)
+	}
+
 	return fullMessage, nil
 }
 
-func (s *MessageService) CreateTranscriptMessage(ctx context.Context, payload *AgentWebhookPayload) (*model.Message, error) {
-	room, err := s.roomRepo.GetByName(ctx, payload.RoomName)
+// resolveMentions maps the @username and @all tokens in content to the
+// room's active participants that have a user account.
+func (s *MessageService) resolveMentions(ctx context.Context, roomID uuid.UUID, content string) ([]*model.RoomParticipant, error) {
+	usernames := utils.ParseMentions(content)
+	if len(usernames) == 0 {
+		return nil, nil
+	}
+
+	var participants []*model.RoomParticipant
+	var err error
+	mentionsAll := false
+	for _, username := range usernames {
+		if username == utils.MentionAll {
+			mentionsAll = true
+			break
+		}
+	}
+	if mentionsAll {
+		participants, err = s.participantRepo.GetByRoomID(ctx, roomID)
+	} else {
+		participants, err = s.participantRepo.GetByRoomAndUsernames(ctx, roomID, usernames)
+	}
 	if err != nil {
 		return nil, err
 	}
-	if room == nil {
-		return nil, errors.New("room not found")
+
+	mentioned := make([]*model.RoomParticipant, 0, len(participants))
+	for _, participant := range participants {
+		if participant.UserID != nil {
+			mentioned = append(mentioned, participant)
+		}
+	}
+	return mentioned, nil
+}
+
+// queueMentions hands the message's mentions to the mention email workers.
+// When they are too far behind, the mentions are logged and not emailed
+// rather than holding up the request.
+func (s *MessageService) queueMentions(room *model.Room, message *model.Message, mentioned []*model.RoomParticipant) {
+	select {
+	case s.mentions <- mentionNotification{room: room, message: message, mentioned: mentioned}:
+	default:
+		log.Warn().
+			Str("room_id", room.ID.String()).
+			Str("message_id", message.ID.String()).
+			Msg("Mention email queue is full, not emailing mentions")
+	}
+}
+
+// sendMentionEmails is a mention email worker.
+func (s *MessageService) sendMentionEmails() {
+	for notification := range s.mentions {
+		s.notifyMentions(notification.room, notification.message, notification.mentioned)
+	}
+}
+
+// notifyMentions emails the mentioned participants that are not connected to
+// the room. It runs after the request has returned, so it uses its own
+// context and only logs failures.
+func (s *MessageService) notifyMentions(room *model.Room, message *model.Message, mentioned []*model.RoomParticipant) {
+	excerpt := message.Content
+	if runes := []rune(excerpt); len(runes) > mentionExcerptLength {
+		excerpt = string(runes[:mentionExcerptLength]) + "…"
+	}
+	roomURL := s.frontendURL + "/app/uroom/" + room.ID.String()
+
+	for _, participant := range mentioned {
+		if message.UserID != nil && *participant.UserID == *message.UserID {
+			continue
+		}
+		if participant.IsOnline(presenceWindow) {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), mentionEmailTimeout)
+		err := s.emailService.SendMentionEmail(ctx, participant.Email, message.Username, room.RoomName, excerpt, roomURL)
+		cancel()
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("room_id", room.ID.String()).
+				Str("message_id", message.ID.String()).
+				Str("participant_id", participant.ID.String()).
+				Msg("Failed to send mention email")
+		}
 	}
+}
 
+// CreateTranscriptMessage posts the transcript announced by the agent. A
+// retried delivery returns the message created by the first one, with
+// created set to false.
+func (s *MessageService) CreateTranscriptMessage(ctx context.Context, payload *AgentWebhookPayload, agentID uuid.UUID) (message *model.Message, created bool, err error) {
 	sessionStart, _ := time.Parse(time.RFC3339, payload.SessionStart)
 	sessionEnd, _ := time.Parse(time.RFC3339, payload.SessionEnd)
-	// TODO (BIPUL): for now setting it to ownder name. In future agent name will be provided
-	systemMessageUserId := room.OwnerID
 
-	message := &model.Message{
-		RoomID:      room.ID,
-		UserID:      &systemMessageUserId, // System message
-		Content:     "Meeting transcript is available.",
-		MessageType: model.MessageTypeMeetingTranscript,
-		ExtraData: &model.ExtraData{
-			Transcript: &model.TranscriptData{
-				Bucket: payload.Bucket,
-				Region: payload.Region,
-				S3Keys: model.S3Keys{
-					JSON: payload.S3Keys.JSON,
-					Text: payload.S3Keys.Text,
-				},
-				HTTPSUrls: model.HTTPSUrls{
-					JSON: payload.TranscriptPaths.JSONHTTPS,
-					Text: payload.TranscriptPaths.TextHTTPS,
-				},
-				SessionStart: sessionStart,
-				SessionEnd:   sessionEnd,
+	extraData := &model.ExtraData{
+		Transcript: &model.TranscriptData{
+			Bucket: payload.Bucket,
+			Region: payload.Region,
+			S3Keys: model.S3Keys{
+				JSON: payload.S3Keys.JSON,
+				Text: payload.S3Keys.Text,
 			},
+			HTTPSUrls: model.HTTPSUrls{
+				JSON: payload.TranscriptPaths.JSONHTTPS,
+				Text: payload.TranscriptPaths.TextHTTPS,
+			},
+			SessionStart: sessionStart,
+			SessionEnd:   sessionEnd,
 		},
 	}
 
+	return s.CreateAgentMessage(ctx, payload.RoomName, agentID, payload.IdempotencyKey(), model.MessageTypeMeetingTranscript, "Meeting transcript is available.", extraData)
+}
+
+// CreateAgentMessage posts a message authored by the agent in the room with
+// the given name. When idempotencyKey is set and a message was already
+// created with it, that message is returned with created set to false
+// instead.
+func (s *MessageService) CreateAgentMessage(ctx context.Context, roomName string, agentID uuid.UUID, idempotencyKey string, messageType model.MessageType, content string, extraData *model.ExtraData) (message *model.Message, created bool, err error) {
+	return s.createSystemMessage(ctx, roomName, &model.Message{
+		AgentID:     &agentID,
+		Content:     content,
+		MessageType: messageType,
+		ExtraData:   extraData,
+	}, idempotencyKey)
+}
+
+// CreateSystemMessage posts a message about something that happened in the
+// room's call, attributed to the user it concerns when there is one. It is
+// idempotent like CreateAgentMessage.
+func (s *MessageService) CreateSystemMessage(ctx context.Context, roomName string, userID *uuid.UUID, idempotencyKey string, messageType model.MessageType, content string, extraData *model.ExtraData) (message *model.Message, created bool, err error) {
+	return s.createSystemMessage(ctx, roomName, &model.Message{
+		UserID:      userID,
+		Content:     content,
+		MessageType: messageType,
+		ExtraData:   extraData,
+	}, idempotencyKey)
+}
+
+func (s *MessageService) createSystemMessage(ctx context.Context, roomName string, message *model.Message, idempotencyKey string) (*model.Message, bool, error) {
+	if idempotencyKey != "" {
+		existing, err := s.messageRepo.GetByIdempotencyKey(ctx, idempotencyKey)
+		if err == nil {
+			return existing, false, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, false, err
+		}
+	}
+
+	room, err := s.roomRepo.GetByName(ctx, roomName)
+	if err != nil {
+		return nil, false, err
+	}
+	if room == nil {
+		return nil, false, ErrRoomNotFound
+	}
+
+	message.RoomID = room.ID
+	if idempotencyKey != "" {
+		message.IdempotencyKey = &idempotencyKey
+	}
+
 	_, err = s.messageRepo.Create(ctx, message)
+	if errors.Is(err, repository.ErrDuplicateIdempotencyKey) {
+		// A concurrent delivery of the same event won the race.
+		existing, err := s.messageRepo.GetByIdempotencyKey(ctx, idempotencyKey)
+		if err != nil {
+			return nil, false, err
+		}
+		return existing, false, nil
+	}
 	if err != nil {
 		log.Error().
 			Err(err).
 			Str("room_id", message.RoomID.String()).
 			Str("message_type", string(message.MessageType)).
-			Msg("Failed to create transcript message in database")
-		return nil, err
+			Msg("Failed to create system message in database")
+		return nil, false, err
 	}
 
 	fullMessage, err := s.messageRepo.GetMessageWithAttachments(ctx, message.ID)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
-	return fullMessage, nil
+	return fullMessage, true, nil
 }
 
-func (s *MessageService) GetMessages(ctx context.Context, roomID, userID uuid.UUID, limit int, before *uuid.UUID) ([]*model.Message, error) {
+// GetMessages returns a page of the room's timeline, oldest first. Paging by
+// seq_no works in both directions; AroundSeq centres the page on a message,
+// for example one picked from search results.
+func (s *MessageService) GetMessages(ctx context.Context, roomID, userID uuid.UUID, limit int, cursor model.MessageCursor) ([]*model.Message, error) {
 	isMember, err := s.participantRepo.UserHasAccess(ctx, roomID, userID)
 	if err != nil {
 		return nil, err
 	}
 	if !isMember {
-		return nil, errors.New("user is not a member of this room")
+		return nil, ErrNotRoomMember
 	}
 
-	messages, err := s.messageRepo.GetByRoomID(ctx, roomID, limit, before)
+	var messages []*model.Message
+	switch {
+	case cursor.BeforeSeq != nil:
+		messages, err = s.messageRepo.GetByRoomIDBeforeSeq(ctx, roomID, *cursor.BeforeSeq, limit)
+	case cursor.AfterSeq != nil:
+		messages, err = s.messageRepo.GetByRoomIDAfterSeq(ctx, roomID, *cursor.AfterSeq, limit, false)
+	case cursor.AroundSeq != nil:
+		messages, err = s.getMessagesAround(ctx, roomID, *cursor.AroundSeq, limit)
+	default:
+		messages, err = s.messageRepo.GetByRoomID(ctx, roomID, limit, cursor.Before)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -170,30 +402,256 @@ func (s *MessageService) GetMessages(ctx context.Context, roomID, userID uuid.UU
 	return messages, nil
 }
 
-func (s *MessageService) UpdateMessage(ctx context.Context, messageID, userID uuid.UUID, content string) error {
+// getMessagesAround returns up to limit messages with aroundSeq in the middle;
+// the message at aroundSeq itself counts towards the older half.
+func (s *MessageService) getMessagesAround(ctx context.Context, roomID uuid.UUID, aroundSeq int, limit int) ([]*model.Message, error) {
+	newerLimit := limit / 2
+	older, err := s.messageRepo.GetByRoomIDBeforeSeq(ctx, roomID, aroundSeq+1, limit-newerLimit)
+	if err != nil {
+		return nil, err
+	}
+	if newerLimit == 0 {
+		return older, nil
+	}
+
+	newer, err := s.messageRepo.GetByRoomIDAfterSeq(ctx, roomID, aroundSeq, newerLimit, false)
+	if err != nil {
+		return nil, err
+	}
+	return append(older, newer...), nil
+}
+
+// GetMentions returns the messages that mention the user across all of the
+// user's rooms, newest first.
+func (s *MessageService) GetMentions(ctx context.Context, userID uuid.UUID, limit int, before *uuid.UUID) ([]*model.Message, error) {
+	if limit <= 0 || limit > maxMentionsPage {
+		limit = maxMentionsPage
+	}
+	return s.messageRepo.GetMentions(ctx, userID, limit, before)
+}
+
+// GetThread returns a thread's root message and a page of its replies.
+// Asking for the thread of a reply returns the thread the reply belongs to.
+func (s *MessageService) GetThread(ctx context.Context, roomID, messageID, userID uuid.UUID, limit int, before *uuid.UUID) (*model.ThreadResponse, error) {
+	isMember, err := s.participantRepo.UserHasAccess(ctx, roomID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, ErrNotRoomMember
+	}
+
+	root, err := s.getThreadRoot(ctx, roomID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	replies, err := s.messageRepo.GetThread(ctx, root.ID, limit, before)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, msg := range replies {
+		fullMsg, err := s.messageRepo.GetMessageWithAttachments(ctx, msg.ID)
+		if err == nil {
+			replies[i] = fullMsg
+		}
+	}
+
+	fullRoot, err := s.messageRepo.GetMessageWithAttachments(ctx, root.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ThreadResponse{Root: fullRoot, Replies: replies}, nil
+}
+
+// getThreadRoot resolves the root of the thread a message belongs to. Threads
+// are one level deep, so replying to a reply lands in the same thread.
+func (s *MessageService) getThreadRoot(ctx context.Context, roomID, messageID uuid.UUID) (*model.Message, error) {
 	message, err := s.messageRepo.GetByID(ctx, messageID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMessageNotFound
+		}
+		return nil, err
+	}
+	if message.RoomID != roomID {
+		return nil, ErrMessageNotFound
+	}
+	if message.ParentMessageID == nil {
+		return message, nil
+	}
+
+	root, err := s.messageRepo.GetByID(ctx, *message.ParentMessageID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMessageNotFound
+		}
+		return nil, err
+	}
+	return root, nil
+}
+
+// UpdateMessage changes the content of a message. Only its author may edit
+// it, and only user messages can be edited; system notices attributed to a
+// user are not theirs to rewrite.
+func (s *MessageService) UpdateMessage(ctx context.Context, roomID, messageID, userID uuid.UUID, content string) error {
+	isMember, err := s.participantRepo.UserHasAccess(ctx, roomID, userID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return ErrNotRoomMember
+	}
+
+	message, err := s.getRoomMessage(ctx, roomID, messageID)
 	if err != nil {
 		return err
 	}
 
-	if message.UserID == nil || *message.UserID != userID {
-		return errors.New("unauthorized to edit this message")
+	if !isUserAuthor(message, userID) {
+		return ErrPermissionDenied
 	}
 
-	return s.messageRepo.Update(ctx, messageID, content)
+	return s.messageRepo.Update(ctx, messageID, userID, content)
 }
 
-func (s *MessageService) DeleteMessage(ctx context.Context, messageID, userID uuid.UUID) error {
-	message, err := s.messageRepo.GetByID(ctx, messageID)
+// DeleteMessage removes a message. Authors may delete their own user
+// messages; room owners and moderators may delete any message in the room.
+func (s *MessageService) DeleteMessage(ctx context.Context, roomID, messageID, userID uuid.UUID) error {
+	participant, err := s.participantRepo.GetByRoomAndUserID(ctx, roomID, userID)
 	if err != nil {
 		return err
 	}
+	if participant == nil || !participant.IsActive {
+		return ErrNotRoomMember
+	}
+
+	message, err := s.getRoomMessage(ctx, roomID, messageID)
+	if err != nil {
+		return err
+	}
+
+	if !isUserAuthor(message, userID) && !participant.CanModerate() {
+		return ErrPermissionDenied
+	}
+
+	return s.messageRepo.Delete(ctx, messageID, userID)
+}
+
+// isUserAuthor reports whether the message is a user message the user wrote.
+func isUserAuthor(message *model.Message, userID uuid.UUID) bool {
+	return message.MessageType == model.MessageTypeUserMessage &&
+		message.UserID != nil && *message.UserID == userID
+}
+
+// GetMessageRevisions returns a message's edit and delete history. Only room
+// owners and moderators may read it.
+func (s *MessageService) GetMessageRevisions(ctx context.Context, roomID, messageID, userID uuid.UUID) ([]*model.MessageRevision, error) {
+	if err := s.requireModerator(ctx, roomID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.messageRepo.GetRevisions(ctx, roomID, messageID)
+}
+
+// PinMessage pins a message to the top of its room and records the pin in
+// the timeline. Pinning an already pinned message changes nothing.
+func (s *MessageService) PinMessage(ctx context.Context, roomID, messageID, userID uuid.UUID) (*model.Message, error) {
+	if err := s.requireModerator(ctx, roomID, userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.getRoomMessage(ctx, roomID, messageID); err != nil {
+		return nil, err
+	}
+
+	pinned, err := s.messageRepo.Pin(ctx, messageID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if pinned {
+		if err := s.createPinMessage(ctx, roomID, messageID, userID, model.MessageTypeMessagePinned, "pinned a message"); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.messageRepo.GetMessageWithAttachments(ctx, messageID)
+}
 
-	if message.UserID == nil || *message.UserID != userID {
-		return errors.New("unauthorized to delete this message")
+// UnpinMessage removes a message from the room's pins and records it in the
+// timeline. Unpinning a message that is not pinned changes nothing.
+func (s *MessageService) UnpinMessage(ctx context.Context, roomID, messageID, userID uuid.UUID) (*model.Message, error) {
+	if err := s.requireModerator(ctx, roomID, userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.getRoomMessage(ctx, roomID, messageID); err != nil {
+		return nil, err
 	}
 
-	return s.messageRepo.Delete(ctx, messageID)
+	unpinned, err := s.messageRepo.Unpin(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if unpinned {
+		if err := s.createPinMessage(ctx, roomID, messageID, userID, model.MessageTypeMessageUnpinned, "unpinned a message"); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.messageRepo.GetMessageWithAttachments(ctx, messageID)
+}
+
+func (s *MessageService) GetPinnedMessages(ctx context.Context, roomID, userID uuid.UUID) ([]*model.Message, error) {
+	isMember, err := s.participantRepo.UserHasAccess(ctx, roomID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, ErrNotRoomMember
+	}
+
+	return s.messageRepo.GetPinned(ctx, roomID)
+}
+
+// createPinMessage posts the system message announcing a pin change, authored
+// by the moderator who made it.
+func (s *MessageService) createPinMessage(ctx context.Context, roomID, messageID, userID uuid.UUID, messageType model.MessageType, content string) error {
+	message := &model.Message{
+		RoomID:      roomID,
+		UserID:      &userID,
+		Content:     content,
+		MessageType: messageType,
+		ExtraData: &model.ExtraData{
+			Pin: &model.PinData{MessageID: messageID},
+		},
+	}
+
+	_, err := s.messageRepo.Create(ctx, message)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("room_id", roomID.String()).
+			Str("message_type", string(messageType)).
+			Msg("Failed to create pin message in database")
+	}
+	return err
+}
+
+// requireModerator verifies that the user is an active owner or moderator of
+// the room.
+func (s *MessageService) requireModerator(ctx context.Context, roomID, userID uuid.UUID) error {
+	participant, err := s.participantRepo.GetByRoomAndUserID(ctx, roomID, userID)
+	if err != nil {
+		return err
+	}
+	if participant == nil || !participant.IsActive {
+		return ErrNotRoomMember
+	}
+	if !participant.CanModerate() {
+		return ErrPermissionDenied
+	}
+	return nil
 }
 
 func (s *MessageService) SearchMessages(ctx context.Context, roomID, userID uuid.UUID, query string, limit int) ([]*model.Message, error) {
@@ -202,48 +660,80 @@ func (s *MessageService) SearchMessages(ctx context.Context, roomID, userID uuid
 		return nil, err
 	}
 	if !isMember {
-		return nil, errors.New("user is not a member of this room")
+		return nil, ErrNotRoomMember
 	}
 
 	return s.messageRepo.Search(ctx, roomID, query, limit)
 }
 
-func (s *MessageService) AddReaction(ctx context.Context, messageID, userID uuid.UUID, emoji string) error {
-	message, err := s.messageRepo.GetByID(ctx, messageID)
+// AddReaction adds the user's reaction to a message and returns the
+// message's reactions afterwards.
+func (s *MessageService) AddReaction(ctx context.Context, roomID, messageID, userID uuid.UUID, emoji string) ([]model.Reaction, error) {
+	if err := s.checkMessageAccess(ctx, roomID, messageID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.reactionRepo.Add(ctx, messageID, userID, emoji); err != nil {
+		return nil, err
+	}
+
+	return s.getReactions(ctx, messageID)
+}
+
+// RemoveReaction withdraws the user's reaction from a message and returns the
+// message's reactions afterwards.
+func (s *MessageService) RemoveReaction(ctx context.Context, roomID, messageID, userID uuid.UUID, emoji string) ([]model.Reaction, error) {
+	if err := s.checkMessageAccess(ctx, roomID, messageID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.reactionRepo.Remove(ctx, messageID, userID, emoji); err != nil {
+		return nil, err
+	}
+
+	return s.getReactions(ctx, messageID)
+}
+
+// checkMessageAccess verifies that the user belongs to the room and that the
+// message is a live message of that room.
+func (s *MessageService) checkMessageAccess(ctx context.Context, roomID, messageID, userID uuid.UUID) error {
+	isMember, err := s.participantRepo.UserHasAccess(ctx, roomID, userID)
 	if err != nil {
 		return err
 	}
-
-	if message.Metadata == nil {
-		message.Metadata = &model.MessageMetadata{
-			Reactions: []model.Reaction{},
-		}
+	if !isMember {
+		return ErrNotRoomMember
 	}
 
-	found := false
-	for i, reaction := range message.Metadata.Reactions {
-		if reaction.Emoji == emoji {
-			for _, id := range reaction.UserIDs {
-				if id == userID {
-					return errors.New("user already reacted with this emoji")
-				}
-			}
-			message.Metadata.Reactions[i].UserIDs = append(reaction.UserIDs, userID)
-			message.Metadata.Reactions[i].Count++
-			found = true
-			break
+	_, err = s.getRoomMessage(ctx, roomID, messageID)
+	return err
+}
+
+// getRoomMessage loads a live message, treating messages of other rooms as
+// missing so IDs cannot be probed across rooms.
+func (s *MessageService) getRoomMessage(ctx context.Context, roomID, messageID uuid.UUID) (*model.Message, error) {
+	message, err := s.messageRepo.GetByID(ctx, messageID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMessageNotFound
 		}
+		return nil, err
 	}
-
-	if !found {
-		message.Metadata.Reactions = append(message.Metadata.Reactions, model.Reaction{
-			Emoji:   emoji,
-			UserIDs: []uuid.UUID{userID},
-			Count:   1,
-		})
+	if message.RoomID != roomID {
+		return nil, ErrMessageNotFound
 	}
+	return message, nil
+}
 
-	return s.messageRepo.UpdateMetadata(ctx, messageID, message.Metadata)
+func (s *MessageService) getReactions(ctx context.Context, messageID uuid.UUID) ([]model.Reaction, error) {
+	message, err := s.messageRepo.GetMessageWithAttachments(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message.Metadata == nil || message.Metadata.Reactions == nil {
+		return []model.Reaction{}, nil
+	}
+	return message.Metadata.Reactions, nil
 }
 
 func (s *MessageService) UpdateLastRead(ctx context.Context, roomID, userID uuid.UUID, lastReadSeqNo int) error {
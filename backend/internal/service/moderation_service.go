@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"livekit-consulting/backend/internal/model"
+	"livekit-consulting/backend/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/livekit/protocol/livekit"
+	"github.com/twitchtv/twirp"
+)
+
+var (
+	// ErrParticipantNotInCall is returned when the participant or track to
+	// moderate is not in the room's call.
+	ErrParticipantNotInCall = errors.New("participant is not in the call")
+	// ErrCallNotStarted is returned when the room has no LiveKit room yet.
+	ErrCallNotStarted = errors.New("livekit room not created for this room yet")
+)
+
+// ModerationService lets a room's owner and moderators act on participants
+// during a call. Every action LiveKit carried out is audit-logged.
+type ModerationService struct {
+	livekitService  *LiveKitService
+	roomRepo        repository.RoomRepository
+	participantRepo repository.ParticipantRepository
+	auditService    *AuditService
+}
+
+func NewModerationService(
+	livekitService *LiveKitService,
+	roomRepo repository.RoomRepository,
+	participantRepo repository.ParticipantRepository,
+	auditService *AuditService,
+) *ModerationService {
+	return &ModerationService{
+		livekitService:  livekitService,
+		roomRepo:        roomRepo,
+		participantRepo: participantRepo,
+		auditService:    auditService,
+	}
+}
+
+// ListCallParticipants returns who is in the room's call right now, with the
+// tracks they publish.
+func (s *ModerationService) ListCallParticipants(ctx context.Context, roomID, userID uuid.UUID) ([]*livekit.ParticipantInfo, error) {
+	room, err := s.requireModerator(ctx, roomID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.livekitService.ListParticipants(ctx, *room.LiveKitRoomName)
+}
+
+// MuteTrack mutes or unmutes a track the participant publishes.
+func (s *ModerationService) MuteTrack(ctx context.Context, roomID uuid.UUID, actor AuditActor, identity, trackSID string, muted bool) (*livekit.TrackInfo, error) {
+	room, err := s.requireCanModerate(ctx, roomID, actor.UserID, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	track, err := s.livekitService.MutePublishedTrack(ctx, *room.LiveKitRoomName, identity, trackSID, muted)
+	if err != nil {
+		return nil, liveKitCallError(err)
+	}
+
+	action := model.AuditActionTrackUnmuted
+	if muted {
+		action = model.AuditActionTrackMuted
+	}
+	details := model.Metadata{"track_sid": trackSID}
+	if err := s.auditService.Record(ctx, roomID, actor, action, "participant", identity, details); err != nil {
+		return nil, err
+	}
+	return track, nil
+}
+
+// RemoveParticipant disconnects the participant from the room's call. It
+// does not take away their room membership.
+func (s *ModerationService) RemoveParticipant(ctx context.Context, roomID uuid.UUID, actor AuditActor, identity string) error {
+	room, err := s.requireCanModerate(ctx, roomID, actor.UserID, identity)
+	if err != nil {
+		return err
+	}
+
+	if err := s.livekitService.RemoveParticipant(ctx, *room.LiveKitRoomName, identity); err != nil {
+		return liveKitCallError(err)
+	}
+
+	return s.auditService.Record(ctx, roomID, actor, model.AuditActionParticipantRemoved, "participant", identity, nil)
+}
+
+// UpdatePermissions changes whether the participant may publish and
+// subscribe for the rest of their time in the call.
+func (s *ModerationService) UpdatePermissions(ctx context.Context, roomID uuid.UUID, actor AuditActor, identity string, req *model.UpdateCallPermissionsRequest) (*livekit.ParticipantInfo, error) {
+	room, err := s.requireCanModerate(ctx, roomID, actor.UserID, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	// LiveKit replaces the whole permission set, so start from the current
+	// one to keep the permissions we are not changing.
+	current, err := s.livekitService.GetParticipant(ctx, *room.LiveKitRoomName, identity)
+	if err != nil {
+		return nil, liveKitCallError(err)
+	}
+	permission := &livekit.ParticipantPermission{}
+	if current.GetPermission() != nil {
+		permission = current.GetPermission()
+	}
+	if req.CanPublish != nil {
+		permission.CanPublish = *req.CanPublish
+	}
+	if req.CanSubscribe != nil {
+		permission.CanSubscribe = *req.CanSubscribe
+	}
+
+	info, err := s.livekitService.UpdateParticipantPermission(ctx, *room.LiveKitRoomName, identity, permission)
+	if err != nil {
+		return nil, liveKitCallError(err)
+	}
+
+	details := model.Metadata{
+		"can_publish":   permission.CanPublish,
+		"can_subscribe": permission.CanSubscribe,
+	}
+	if err := s.auditService.Record(ctx, roomID, actor, model.AuditActionPermissionsUpdated, "participant", identity, details); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// requireModerator checks that the user is an owner or moderator of a room
+// that has a LiveKit room.
+func (s *ModerationService) requireModerator(ctx context.Context, roomID, userID uuid.UUID) (*model.Room, error) {
+	room, err := s.roomRepo.GetByID(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	participant, err := s.participantRepo.GetByRoomAndUserID(ctx, roomID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if participant == nil || !participant.IsActive {
+		return nil, ErrNotRoomMember
+	}
+	if !participant.CanModerate() {
+		return nil, ErrPermissionDenied
+	}
+
+	if room.LiveKitRoomName == nil || *room.LiveKitRoomName == "" {
+		return nil, ErrCallNotStarted
+	}
+	return room, nil
+}
+
+// requireCanModerate checks that the user may moderate the participant with
+// the given identity. Only the owner may act on the owner, so moderators may
+// only act on people we can tell are not the owner.
+func (s *ModerationService) requireCanModerate(ctx context.Context, roomID, userID uuid.UUID, identity string) (*model.Room, error) {
+	room, err := s.requireModerator(ctx, roomID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if room.OwnerID == userID {
+		return room, nil
+	}
+
+	target, err := s.participantRepo.GetByRoomAndIdentity(ctx, roomID, identity)
+	if err != nil {
+		return nil, err
+	}
+	if target != nil {
+		if target.Role == model.RoleOwner || (target.UserID != nil && *target.UserID == room.OwnerID) {
+			return nil, ErrPermissionDenied
+		}
+		return room, nil
+	}
+
+	// Not a room member we know by that identity: go by what their token
+	// says about them.
+	info, err := s.livekitService.GetParticipant(ctx, *room.LiveKitRoomName, identity)
+	if err != nil {
+		return nil, liveKitCallError(err)
+	}
+	if info.GetKind() == livekit.ParticipantInfo_AGENT {
+		return room, nil
+	}
+	var metadata participantTokenMetadata
+	if err := json.Unmarshal([]byte(info.GetMetadata()), &metadata); err != nil || metadata.Role == "" || metadata.Role == model.RoleOwner {
+		return nil, ErrPermissionDenied
+	}
+	return room, nil
+}
+
+// liveKitCallError maps LiveKit's not-found errors for participants and
+// tracks to ErrParticipantNotInCall.
+func liveKitCallError(err error) error {
+	var twerr twirp.Error
+	if errors.As(err, &twerr) && twerr.Code() == twirp.NotFound {
+		return ErrParticipantNotInCall
+	}
+	return err
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"livekit-consulting/backend/internal/model"
 	"livekit-consulting/backend/internal/repository"
@@ -48,6 +49,21 @@ func (s *ParticipantService) AddParticipant(
 	if err != nil {
 		return nil, err
 	}
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	// Only hosts add people, and only the owner makes someone a moderator.
+	inviter, err := s.participantRepo.GetByRoomAndUserID(ctx, roomID, inviterID)
+	if err != nil {
+		return nil, err
+	}
+	if inviter == nil || !inviter.CanModerate() {
+		return nil, ErrPermissionDenied
+	}
+	if req.Role == model.RoleModerator && room.OwnerID != inviterID {
+		return nil, ErrPermissionDenied
+	}
 
 	existing, err := s.participantRepo.GetByRoomAndEmail(ctx, roomID, req.Email)
 	if err != nil {
@@ -64,7 +80,10 @@ func (s *ParticipantService) AddParticipant(
 		RoomID:        roomID,
 		Email:         req.Email,
 		Name:          req.Name,
-		Role:          "participant",
+		Role:          model.RoleParticipant,
+	}
+	if req.Role != "" {
+		participant.Role = req.Role
 	}
 
 	err = s.participantRepo.Create(ctx, participant)
@@ -174,15 +193,23 @@ func (s *ParticipantService) GenerateParticipantToken(
 	if err != nil {
 		return "", err
 	}
+	if room == nil {
+		return "", errors.New("room not found")
+	}
+	if room.LiveKitRoomName == nil || *room.LiveKitRoomName == "" {
+		return "", errors.New("livekit room not created for this room yet")
+	}
 
-	identity := invite.InviteeEmail
-	token, err := s.livekitService.GenerateToken(
-		identity,
-		*room.LiveKitRoomName,
-		true,
-		true,
-	)
+	participant, err := s.participantRepo.GetByRoomAndEmail(ctx, roomID, invite.InviteeEmail)
+	if err != nil {
+		return "", err
+	}
+	if participant == nil || !participant.IsActive {
+		// The invitee was removed from the room after being invited.
+		return "", ErrPermissionDenied
+	}
 
+	token, err := s.generateToken(room, participant)
 	if err != nil {
 		return "", err
 	}
@@ -212,17 +239,39 @@ func (s *ParticipantService) GenerateInternalParticipantToken(ctx context.Contex
 		return "", errors.New("livekit room not created for this room yet")
 	}
 
-	identity := participant.Email
-	token, err := s.livekitService.GenerateToken(
-		identity,
-		*room.LiveKitRoomName,
-		true, // canPublish
-		true, // canSubscribe
-	)
+	return s.generateToken(room, participant)
+}
 
+// participantTokenMetadata is the metadata set on a participant's LiveKit
+// token, visible to everyone in the call.
+type participantTokenMetadata struct {
+	RoomID        uuid.UUID `json:"room_id"`
+	ParticipantID uuid.UUID `json:"participant_id"`
+	Role          string    `json:"role"`
+}
+
+// generateToken issues the participant a LiveKit token for the room, with
+// the grants of their role and the room's token lifetime. Their email is
+// their identity in the call.
+func (s *ParticipantService) generateToken(room *model.Room, participant *model.RoomParticipant) (string, error) {
+	metadata := participantTokenMetadata{
+		RoomID:        room.ID,
+		ParticipantID: participant.ID,
+		Role:          participant.Role,
+	}
+	encoded, err := json.Marshal(metadata)
 	if err != nil {
 		return "", err
 	}
 
-	return token, nil
+	opts := TokenOptions{
+		Role:     participant.Role,
+		Name:     participant.Name,
+		Metadata: string(encoded),
+	}
+	if room.TokenTTLSeconds != nil {
+		opts.TTL = time.Duration(*room.TokenTTLSeconds) * time.Second
+	}
+
+	return s.livekitService.GenerateToken(participant.Email, *room.LiveKitRoomName, opts)
 }
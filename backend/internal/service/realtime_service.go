@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"livekit-consulting/backend/internal/events"
+	"livekit-consulting/backend/internal/model"
+	"livekit-consulting/backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// maxReplayMessages caps how many missed messages are replayed to a
+// reconnecting client. Clients further behind are told to reload the room.
+const maxReplayMessages = 500
+
+// RoomEvent is the payload pushed to realtime clients for every room event.
+type RoomEvent struct {
+	Type      events.Type     `json:"type"`
+	RoomID    uuid.UUID       `json:"room_id"`
+	SeqNo     int             `json:"seq_no,omitempty"`
+	MessageID *uuid.UUID      `json:"message_id,omitempty"`
+	Message   *model.Message  `json:"message,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type RealtimeService struct {
+	bus             events.Bus
+	messageRepo     repository.MessageRepository
+	participantRepo repository.ParticipantRepository
+	roomRepo        repository.RoomRepository
+}
+
+func NewRealtimeService(
+	bus events.Bus,
+	messageRepo repository.MessageRepository,
+	participantRepo repository.ParticipantRepository,
+	roomRepo repository.RoomRepository,
+) *RealtimeService {
+	return &RealtimeService{
+		bus:             bus,
+		messageRepo:     messageRepo,
+		participantRepo: participantRepo,
+		roomRepo:        roomRepo,
+	}
+}
+
+// Authorize checks that the user may follow the room's activity.
+func (s *RealtimeService) Authorize(ctx context.Context, roomID, userID uuid.UUID) error {
+	isMember, err := s.participantRepo.UserHasAccess(ctx, roomID, userID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return ErrNotRoomMember
+	}
+	return nil
+}
+
+// CanReceive reports whether the user may be sent the event. Events meant
+// for hosts only go to the room's owner and moderators.
+func (s *RealtimeService) CanReceive(ctx context.Context, userID uuid.UUID, event events.Event) (bool, error) {
+	if !event.Type.HostsOnly() {
+		return true, nil
+	}
+	participant, err := s.participantRepo.GetByRoomAndUserID(ctx, event.RoomID, userID)
+	if err != nil {
+		return false, err
+	}
+	return participant != nil && participant.CanModerate(), nil
+}
+
+// Touch records that the user is connected to the room right now. Realtime
+// connections call it periodically so mention notifications can tell who is
+// online.
+func (s *RealtimeService) Touch(ctx context.Context, roomID, userID uuid.UUID) error {
+	return s.participantRepo.UpdateLastSeen(ctx, roomID, userID)
+}
+
+// LastSeq returns the room's last_message_seq, the position a client that
+// starts following the room now would resume from.
+func (s *RealtimeService) LastSeq(ctx context.Context, roomID uuid.UUID) (int, error) {
+	room, err := s.roomRepo.GetByID(ctx, roomID)
+	if err != nil {
+		return 0, err
+	}
+	if room == nil {
+		return 0, errors.New("room not found")
+	}
+	return room.LastMessageSeq, nil
+}
+
+// Subscribe starts listening for events in a room. Subscribe before calling
+// Replay so that nothing published in between is lost.
+func (s *RealtimeService) Subscribe(roomID uuid.UUID) *events.Subscription {
+	return s.bus.Subscribe(roomID)
+}
+
+// Replay returns a message.created event for every message the client missed
+// since sinceSeq, oldest first. When it missed more than maxReplayMessages,
+// Replay returns a single replay.truncated event positioned at the room's
+// last message instead.
+func (s *RealtimeService) Replay(ctx context.Context, roomID uuid.UUID, sinceSeq int) ([]*RoomEvent, error) {
+	messages, err := s.messageRepo.GetByRoomIDAfterSeq(ctx, roomID, sinceSeq, maxReplayMessages+1, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) > maxReplayMessages {
+		lastSeq, err := s.LastSeq(ctx, roomID)
+		if err != nil {
+			return nil, err
+		}
+		data, _ := json.Marshal(map[string]int{"last_message_seq": lastSeq})
+		return []*RoomEvent{{
+			Type:      events.ReplayTruncated,
+			RoomID:    roomID,
+			SeqNo:     lastSeq,
+			Data:      data,
+			CreatedAt: time.Now(),
+		}}, nil
+	}
+
+	replay := make([]*RoomEvent, 0, len(messages))
+	for _, msg := range messages {
+		fullMsg, err := s.messageRepo.GetMessageWithAttachments(ctx, msg.ID)
+		if err == nil {
+			msg = fullMsg
+		}
+		replay = append(replay, &RoomEvent{
+			Type:      events.MessageCreated,
+			RoomID:    roomID,
+			SeqNo:     msg.SeqNo,
+			MessageID: &msg.ID,
+			Message:   msg,
+			CreatedAt: msg.CreatedAt,
+		})
+	}
+
+	return replay, nil
+}
+
+// Resolve turns a bus event into the payload sent to clients, loading the
+// current state of the referenced message when there is one.
+func (s *RealtimeService) Resolve(ctx context.Context, event events.Event) (*RoomEvent, error) {
+	roomEvent := &RoomEvent{
+		Type:      event.Type,
+		RoomID:    event.RoomID,
+		SeqNo:     event.SeqNo,
+		MessageID: event.MessageID,
+		Data:      event.Data,
+		CreatedAt: event.CreatedAt,
+	}
+
+	if event.MessageID == nil || event.Type == events.MessageDeleted {
+		return roomEvent, nil
+	}
+
+	message, err := s.messageRepo.GetMessageWithAttachments(ctx, *event.MessageID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Deleted before we got to it; a message.deleted event follows.
+			return roomEvent, nil
+		}
+		return nil, err
+	}
+	roomEvent.Message = message
+
+	return roomEvent, nil
+}
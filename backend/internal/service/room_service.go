@@ -43,6 +43,7 @@ func (s *RoomService) CreateRoom(ctx context.Context, userID uuid.UUID, req *mod
         OwnerID:         userID,
         LiveKitRoomName: &livekitRoomName,
         RoomSID:         &lkRoom.Sid,
+        TokenTTLSeconds: req.TokenTTLSeconds,
     }
     
     err = s.roomRepo.Create(ctx, room)
@@ -149,6 +150,27 @@ func (s *RoomService) DeleteRoom(ctx context.Context, roomID, userID uuid.UUID)
     return s.roomRepo.Delete(ctx, roomID)
 }
 
+// UpdateRoomSettings changes the room's settings. Only the room owner may.
+func (s *RoomService) UpdateRoomSettings(ctx context.Context, roomID, userID uuid.UUID, req *model.UpdateRoomSettingsRequest) (*model.Room, error) {
+    room, err := s.roomRepo.GetByID(ctx, roomID)
+    if err != nil {
+        return nil, err
+    }
+    if room == nil {
+        return nil, ErrRoomNotFound
+    }
+    if room.OwnerID != userID {
+        return nil, ErrPermissionDenied
+    }
+
+    room.TokenTTLSeconds = req.TokenTTLSeconds
+    if err := s.roomRepo.UpdateTokenTTL(ctx, room); err != nil {
+        return nil, err
+    }
+
+    return room, nil
+}
+
 func (s *RoomService) CreateLiveKitRoom(ctx context.Context, roomID, userID uuid.UUID) (*livekit.Room, error) {
     participant, err := s.participantRepo.GetByRoomAndUserID(ctx, roomID, userID)
     if err != nil {
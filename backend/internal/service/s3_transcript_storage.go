@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 
 	"livekit-consulting/backend/internal/config"
@@ -10,18 +12,16 @@ import (
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/minio/minio-go/v7"
 )
 
-type S3TranscriptStorage interface {
-	GetTranscriptFile(ctx context.Context, key string) (io.ReadCloser, error)
-}
-
 type s3TranscriptStorage struct {
 	s3Client *s3.Client
 	bucket   string
 }
 
-func NewS3TranscriptStorage(cfg *config.Config) (S3TranscriptStorage, error) {
+func NewS3TranscriptStorage(cfg *config.Config) (TranscriptStorage, error) {
 	awsCfg, err := awsconfig.LoadDefaultConfig(context.TODO(),
 		awsconfig.WithRegion(cfg.TranscriptAWSRegion),
 		awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(cfg.TranscriptAWSAccessKeyID, cfg.TranscriptAWSSecretAccessKey, "")),
@@ -36,7 +36,7 @@ func NewS3TranscriptStorage(cfg *config.Config) (S3TranscriptStorage, error) {
 
 	return &s3TranscriptStorage{
 		s3Client: s3Client,
-		bucket:   cfg.TranscriptAWSBucket,
+		bucket:   transcriptBucket(cfg, cfg.TranscriptAWSBucket),
 	}, nil
 }
 
@@ -45,9 +45,50 @@ func (s *s3TranscriptStorage) GetTranscriptFile(ctx context.Context, key string)
 		Bucket: aws.String(s.bucket),
 		Key:    aws.String(key),
 	})
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return nil, ErrTranscriptFileNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	return obj.Body, nil
 }
+
+// minioTranscriptStorage reads transcripts from an S3-compatible MinIO
+// server through the attachment storage's client.
+type minioTranscriptStorage struct {
+	minioClient *minio.Client
+	bucket      string
+}
+
+func NewMinioTranscriptStorage(cfg *config.Config, fileStorage FileStorage) (TranscriptStorage, error) {
+	files, ok := fileStorage.(*MinioFileStorage)
+	if !ok {
+		return nil, fmt.Errorf("minio transcript storage requires STORAGE_PROVIDER=minio")
+	}
+
+	return &minioTranscriptStorage{
+		minioClient: files.minioClient,
+		bucket:      transcriptBucket(cfg, files.bucket),
+	}, nil
+}
+
+func (s *minioTranscriptStorage) GetTranscriptFile(ctx context.Context, key string) (io.ReadCloser, error) {
+	obj, err := s.minioClient.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// GetObject is lazy; Stat surfaces a missing object before we hand the
+	// reader out.
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrTranscriptFileNotFound
+		}
+		return nil, err
+	}
+
+	return obj, nil
+}
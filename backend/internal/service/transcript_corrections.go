@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"livekit-consulting/backend/internal/model"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrTranscriptSegmentNotFound is returned when a transcript has no
+	// segment at the requested index.
+	ErrTranscriptSegmentNotFound = errors.New("transcript segment not found")
+	// ErrTranscriptVersionNotFound is returned when a transcript has no
+	// version with the requested number.
+	ErrTranscriptVersionNotFound = errors.New("transcript version not found")
+)
+
+// CorrectSegment fixes the text, and optionally the speaker name, of one
+// transcript segment. Every correction is kept as a new transcript version
+// attributed to its editor. Owners and moderators may correct transcripts.
+func (s *TranscriptService) CorrectSegment(ctx context.Context, roomID, messageID uuid.UUID, segmentIndex int, userID uuid.UUID, req *model.CorrectTranscriptSegmentRequest) (*model.TranscriptSegment, error) {
+	participant, err := s.participantRepo.GetByRoomAndUserID(ctx, roomID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if participant == nil || !participant.IsActive {
+		return nil, ErrNotRoomMember
+	}
+	if !participant.CanModerate() {
+		return nil, ErrPermissionDenied
+	}
+
+	message, err := s.getTranscriptMessage(ctx, roomID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureSegments(ctx, message); err != nil {
+		return nil, err
+	}
+
+	var speakerName *string
+	if req.SpeakerName != nil {
+		name := strings.TrimSpace(*req.SpeakerName)
+		speakerName = &name
+	}
+
+	segment, err := s.transcriptRepo.CorrectSegment(ctx, messageID, segmentIndex, userID, strings.TrimSpace(req.Text), speakerName)
+	if err != nil {
+		return nil, err
+	}
+	if segment == nil {
+		return nil, ErrTranscriptSegmentNotFound
+	}
+	return segment, nil
+}
+
+// GetTranscriptRevisions returns every correction of a transcript, oldest
+// first; the version of each is the transcript version it created.
+func (s *TranscriptService) GetTranscriptRevisions(ctx context.Context, roomID, messageID, userID uuid.UUID) ([]*model.TranscriptSegmentRevision, error) {
+	isMember, err := s.participantRepo.UserHasAccess(ctx, roomID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, ErrNotRoomMember
+	}
+
+	if _, err := s.getTranscriptMessage(ctx, roomID, messageID); err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.transcriptRepo.GetRevisions(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	redactor, err := s.redactionService.RedactorForRoom(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	for _, revision := range revisions {
+		revision.PreviousText = redactor.Redact(revision.PreviousText)
+		revision.NewText = redactor.Redact(revision.NewText)
+	}
+	return revisions, nil
+}
+
+// ensureSegments ingests a transcript uploaded before segments were stored,
+// so it can be corrected like any other.
+func (s *TranscriptService) ensureSegments(ctx context.Context, message *model.Message) error {
+	segments, err := s.transcriptRepo.GetSegments(ctx, message.ID, -1, 1)
+	if err != nil || len(segments) > 0 {
+		return err
+	}
+	return s.IngestTranscript(ctx, message)
+}
+
+// applyTranscriptCorrections rewrites a stored transcript file to read as the
+// given segments. In the JSON file, the items that produced segments get the
+// segment's text and speaker name, keeping every other field; the plain text
+// file is rendered from the segments.
+func applyTranscriptCorrections(body []byte, segments []*model.TranscriptSegment, isText bool) ([]byte, error) {
+	if isText {
+		var text strings.Builder
+		for _, segment := range segments {
+			text.WriteString(speakerLabel(segment) + ": " + segment.Text + "\n")
+		}
+		return []byte(text.String()), nil
+	}
+
+	var document map[string]interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return nil, err
+	}
+	items, _ := document["items"].([]interface{})
+
+	// Segments are numbered over the items with text, as ingestion skips
+	// the others.
+	next := 0
+	for _, raw := range items {
+		item, ok := raw.(map[string]interface{})
+		if !ok || !transcriptItemHasText(item) {
+			continue
+		}
+		if next == len(segments) {
+			break
+		}
+		segment := segments[next]
+		next++
+
+		item["content"] = []interface{}{
+			map[string]interface{}{"type": "text", "text": segment.Text},
+		}
+		if _, ok := item["speaker_name"]; ok || segment.SpeakerName != "" {
+			item["speaker_name"] = segment.SpeakerName
+		}
+	}
+
+	return json.Marshal(document)
+}
+
+func transcriptItemHasText(item map[string]interface{}) bool {
+	contents, _ := item["content"].([]interface{})
+	for _, raw := range contents {
+		content, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if text, _ := content["text"].(string); strings.TrimSpace(text) != "" {
+			return true
+		}
+	}
+	return false
+}
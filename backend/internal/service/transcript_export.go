@@ -0,0 +1,272 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+	"unicode"
+
+	"livekit-consulting/backend/internal/model"
+)
+
+// TranscriptFormat is a format a stored transcript can be exported to.
+type TranscriptFormat string
+
+const (
+	TranscriptFormatJSON     TranscriptFormat = "json"
+	TranscriptFormatSRT      TranscriptFormat = "srt"
+	TranscriptFormatWebVTT   TranscriptFormat = "vtt"
+	TranscriptFormatMarkdown TranscriptFormat = "md"
+	TranscriptFormatDOCX     TranscriptFormat = "docx"
+)
+
+// TranscriptExport is a rendered transcript ready to be downloaded.
+type TranscriptExport struct {
+	Filename    string
+	ContentType string
+	Body        []byte
+}
+
+// transcriptDocument is everything a renderer needs to know about a transcript.
+type transcriptDocument struct {
+	RoomName     string
+	SessionStart time.Time
+	Segments     []*model.TranscriptSegment
+}
+
+type transcriptRenderer struct {
+	contentType string
+	render      func(doc *transcriptDocument) ([]byte, error)
+}
+
+var transcriptRenderers = map[TranscriptFormat]transcriptRenderer{
+	TranscriptFormatSRT:      {"application/x-subrip", renderSRT},
+	TranscriptFormatWebVTT:   {"text/vtt", renderWebVTT},
+	TranscriptFormatMarkdown: {"text/markdown", renderMarkdown},
+	TranscriptFormatDOCX:     {"application/vnd.openxmlformats-officedocument.wordprocessingml.document", renderDOCX},
+}
+
+// ParseTranscriptFormat maps a format query parameter to a TranscriptFormat.
+func ParseTranscriptFormat(format string) (TranscriptFormat, bool) {
+	switch strings.ToLower(format) {
+	case "json":
+		return TranscriptFormatJSON, true
+	case "srt":
+		return TranscriptFormatSRT, true
+	case "vtt", "webvtt":
+		return TranscriptFormatWebVTT, true
+	case "md", "markdown":
+		return TranscriptFormatMarkdown, true
+	case "docx":
+		return TranscriptFormatDOCX, true
+	}
+	return "", false
+}
+
+// TranscriptFormatFromAccept returns the first export format named in an
+// Accept header, or false when it only asks for JSON or anything.
+func TranscriptFormatFromAccept(accept string) (TranscriptFormat, bool) {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		for format, renderer := range transcriptRenderers {
+			if mediaType == renderer.contentType {
+				return format, true
+			}
+		}
+		if mediaType == "text/x-markdown" {
+			return TranscriptFormatMarkdown, true
+		}
+	}
+	return "", false
+}
+
+func renderTranscript(doc *transcriptDocument, format TranscriptFormat) (*TranscriptExport, error) {
+	renderer, ok := transcriptRenderers[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported transcript format %q", format)
+	}
+
+	body, err := renderer.render(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TranscriptExport{
+		Filename:    transcriptFilename(doc, format),
+		ContentType: renderer.contentType,
+		Body:        body,
+	}, nil
+}
+
+// transcriptFilename builds a download name such as
+// "weekly-sync_2024-05-01_1200.srt" from the room name and session start.
+func transcriptFilename(doc *transcriptDocument, format TranscriptFormat) string {
+	var name strings.Builder
+	dash := false
+	for _, r := range strings.TrimSpace(doc.RoomName) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			name.WriteRune(unicode.ToLower(r))
+			dash = false
+		} else if !dash && name.Len() > 0 {
+			name.WriteByte('-')
+			dash = true
+		}
+	}
+	base := strings.TrimSuffix(name.String(), "-")
+	if base == "" {
+		base = "transcript"
+	}
+	return base + "_" + doc.SessionStart.UTC().Format("2006-01-02_1504") + "." + string(format)
+}
+
+func speakerLabel(segment *model.TranscriptSegment) string {
+	switch {
+	case segment.SpeakerName != "":
+		return segment.SpeakerName
+	case segment.SpeakerIdentity != "":
+		return segment.SpeakerIdentity
+	case segment.Role != "":
+		return segment.Role
+	}
+	return "Speaker"
+}
+
+// cueEnd makes sure every cue is visible for a moment, since the last
+// utterance of a session can end where it starts.
+func cueEnd(segment *model.TranscriptSegment) int64 {
+	if segment.EndMs > segment.StartMs {
+		return segment.EndMs
+	}
+	return segment.StartMs + 1000
+}
+
+func formatCueTime(ms int64, fractionSep string) string {
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, fractionSep, ms%1000)
+}
+
+func formatOffset(ms int64) string {
+	return fmt.Sprintf("%02d:%02d:%02d", ms/3600000, ms/60000%60, ms/1000%60)
+}
+
+func renderSRT(doc *transcriptDocument) ([]byte, error) {
+	var b bytes.Buffer
+	for i, segment := range doc.Segments {
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s: %s\n\n",
+			i+1,
+			formatCueTime(segment.StartMs, ","),
+			formatCueTime(cueEnd(segment), ","),
+			speakerLabel(segment),
+			segment.Text,
+		)
+	}
+	return b.Bytes(), nil
+}
+
+var vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func renderWebVTT(doc *transcriptDocument) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("WEBVTT\n\n")
+	for _, segment := range doc.Segments {
+		fmt.Fprintf(&b, "%s --> %s\n<v %s>%s\n\n",
+			formatCueTime(segment.StartMs, "."),
+			formatCueTime(cueEnd(segment), "."),
+			vttEscaper.Replace(speakerLabel(segment)),
+			vttEscaper.Replace(segment.Text),
+		)
+	}
+	return b.Bytes(), nil
+}
+
+// markdownEscaper backslash-escapes the characters Markdown would read as
+// formatting, links or HTML in room names, speaker names and spoken text.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "#", `\#`, "[", `\[`, "]", `\]`,
+	"(", `\(`, ")", `\)`, "!", `\!`, "|", `\|`, "<", `\<`, ">", `\>`, "~", `\~`,
+)
+
+func renderMarkdown(doc *transcriptDocument) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# %s transcript\n\n", markdownEscaper.Replace(doc.RoomName))
+	fmt.Fprintf(&b, "_Session started %s_\n\n", doc.SessionStart.UTC().Format("2006-01-02 15:04 MST"))
+	for _, segment := range doc.Segments {
+		fmt.Fprintf(&b, "**[%s] %s:** %s\n\n", formatOffset(segment.StartMs),
+			markdownEscaper.Replace(speakerLabel(segment)), markdownEscaper.Replace(segment.Text))
+	}
+	return b.Bytes(), nil
+}
+
+const docxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>
+</Types>`
+
+const docxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
+</Relationships>`
+
+// renderDOCX writes a minimal WordprocessingML package: one paragraph per
+// utterance with the timestamp and speaker in bold.
+func renderDOCX(doc *transcriptDocument) ([]byte, error) {
+	var body bytes.Buffer
+	body.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`)
+	body.WriteString(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>`)
+	writeDOCXParagraph(&body, doc.RoomName+" transcript", "", `<w:sz w:val="32"/>`)
+	writeDOCXParagraph(&body, "Session started "+doc.SessionStart.UTC().Format("2006-01-02 15:04 MST"), "", "")
+	for _, segment := range doc.Segments {
+		writeDOCXParagraph(&body, segment.Text, "["+formatOffset(segment.StartMs)+"] "+speakerLabel(segment)+": ", "")
+	}
+	body.WriteString(`</w:body></w:document>`)
+
+	var b bytes.Buffer
+	zw := zip.NewWriter(&b)
+	parts := []struct {
+		name    string
+		content []byte
+	}{
+		{"[Content_Types].xml", []byte(docxContentTypes)},
+		{"_rels/.rels", []byte(docxRels)},
+		{"word/document.xml", body.Bytes()},
+	}
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Write(part.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// writeDOCXParagraph appends a paragraph with an optional bold prefix run.
+// runProps is extra run formatting for the main text.
+func writeDOCXParagraph(b *bytes.Buffer, text, boldPrefix, runProps string) {
+	b.WriteString("<w:p>")
+	if boldPrefix != "" {
+		b.WriteString(`<w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">`)
+		xml.EscapeText(b, []byte(boldPrefix))
+		b.WriteString("</w:t></w:r>")
+	}
+	b.WriteString("<w:r>")
+	if runProps != "" {
+		b.WriteString("<w:rPr>" + runProps + "</w:rPr>")
+	}
+	b.WriteString(`<w:t xml:space="preserve">`)
+	xml.EscapeText(b, []byte(text))
+	b.WriteString("</w:t></w:r></w:p>")
+}
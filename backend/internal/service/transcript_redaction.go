@@ -0,0 +1,342 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"livekit-consulting/backend/internal/model"
+	"livekit-consulting/backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidRedactionPattern is returned when a custom redaction pattern is
+// not a valid regular expression.
+var ErrInvalidRedactionPattern = errors.New("invalid redaction pattern")
+
+// redactionDetectors are the built-in categories. Card numbers are checked
+// before phone numbers, whose pattern would otherwise claim their digits.
+// Phone numbers need a country code or grouped digits, so dates, amounts and
+// reference numbers written as a plain run of digits are left alone.
+var redactionDetectors = []struct {
+	category string
+	pattern  *regexp.Regexp
+	valid    func(match string) bool
+}{
+	{model.RedactionEmail, regexp.MustCompile(`(?i)\b[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}\b`), nil},
+	{model.RedactionCard, regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`), luhnValid},
+	{model.RedactionSSN, regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`), nil},
+	{model.RedactionPhone, regexp.MustCompile(`(?:\+\d{1,3}[\s.-]?(?:\(\d{1,4}\)|\d{1,4})(?:[\s.-]?\d{2,4}){2,3}|(?:\(\d{2,4}\)\s?|\b\d{2,4}[\s.-])\d{3,4}[\s.-]\d{4})\b`), nil},
+	{model.RedactionIP, regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1?\d?\d)\.){3}(?:25[0-5]|2[0-4]\d|1?\d?\d)\b`), nil},
+}
+
+// transcriptMetadataKeys are fields of the agent's transcript file that hold
+// identifiers and timestamps rather than spoken text; they are never redacted.
+var transcriptMetadataKeys = map[string]bool{
+	"room_name":        true,
+	"session_start":    true,
+	"session_end":      true,
+	"timestamp":        true,
+	"role":             true,
+	"type":             true,
+	"speaker_identity": true,
+	"interrupted":      true,
+}
+
+type redactionRule struct {
+	mask    string
+	pattern *regexp.Regexp
+	valid   func(match string) bool
+}
+
+// Redactor masks personal data in transcript text. A nil Redactor leaves
+// text unchanged.
+type Redactor struct {
+	rules []redactionRule
+}
+
+// NewRedactor builds a redactor for the given built-in categories and custom
+// patterns. Matches are replaced by the upper-cased category or pattern name
+// in brackets, e.g. [EMAIL].
+func NewRedactor(categories []string, patterns []model.RedactionPattern) (*Redactor, error) {
+	enabled := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		enabled[strings.ToLower(strings.TrimSpace(category))] = true
+	}
+
+	r := &Redactor{}
+	for _, detector := range redactionDetectors {
+		if enabled[detector.category] {
+			r.rules = append(r.rules, redactionRule{
+				mask:    redactionMask(detector.category),
+				pattern: detector.pattern,
+				valid:   detector.valid,
+			})
+		}
+	}
+	for _, p := range patterns {
+		pattern, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %v", ErrInvalidRedactionPattern, p.Name, err)
+		}
+		r.rules = append(r.rules, redactionRule{
+			mask:    redactionMask(p.Name),
+			pattern: pattern,
+		})
+	}
+	return r, nil
+}
+
+func redactionMask(name string) string {
+	return "[" + strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(name), " ", "_")) + "]"
+}
+
+// Redact returns text with every match of the redactor's rules masked.
+func (r *Redactor) Redact(text string) string {
+	if r == nil {
+		return text
+	}
+	for _, rule := range r.rules {
+		text = rule.pattern.ReplaceAllStringFunc(text, func(match string) string {
+			if rule.valid != nil && !rule.valid(match) {
+				return match
+			}
+			return rule.mask
+		})
+	}
+	return text
+}
+
+// RedactSegments returns copies of the segments with their text redacted.
+func (r *Redactor) RedactSegments(segments []*model.TranscriptSegment) []*model.TranscriptSegment {
+	if r == nil {
+		return segments
+	}
+	redacted := make([]*model.TranscriptSegment, len(segments))
+	for i, segment := range segments {
+		copied := *segment
+		copied.Text = r.Redact(segment.Text)
+		redacted[i] = &copied
+	}
+	return redacted
+}
+
+// RedactTranscriptFile redacts a transcript file as stored by the agent. JSON
+// files have the text of every field redacted except the known metadata
+// fields, keeping their structure; anything else is redacted as plain text.
+func (r *Redactor) RedactTranscriptFile(data []byte) ([]byte, error) {
+	if r == nil {
+		return data, nil
+	}
+
+	var document interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&document); err != nil {
+		return []byte(r.Redact(string(data))), nil
+	}
+	return json.Marshal(r.redactJSONValue(document))
+}
+
+func (r *Redactor) redactJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return r.Redact(v)
+	case []interface{}:
+		for i := range v {
+			v[i] = r.redactJSONValue(v[i])
+		}
+	case map[string]interface{}:
+		for key, field := range v {
+			if !transcriptMetadataKeys[key] {
+				v[key] = r.redactJSONValue(field)
+			}
+		}
+	}
+	return value
+}
+
+// luhnValid reports whether the digits of s pass the Luhn checksum, which
+// every payment card number does.
+func luhnValid(s string) bool {
+	sum, digits := 0, 0
+	double := false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+		double = !double
+	}
+	return digits >= 13 && sum%10 == 0
+}
+
+// ParseRedactionPatterns parses configured patterns written as
+// name=regexp.
+func ParseRedactionPatterns(specs []string) ([]model.RedactionPattern, error) {
+	var patterns []model.RedactionPattern
+	for _, spec := range specs {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		name, pattern, ok := strings.Cut(spec, "=")
+		if !ok || strings.TrimSpace(name) == "" || pattern == "" {
+			return nil, fmt.Errorf("%w: %q is not name=regexp", ErrInvalidRedactionPattern, spec)
+		}
+		patterns = append(patterns, model.RedactionPattern{Name: strings.TrimSpace(name), Pattern: pattern})
+	}
+	return patterns, nil
+}
+
+// RedactionDefaults is the policy of rooms that have not set their own.
+type RedactionDefaults struct {
+	Enabled    bool
+	Categories []string
+	// Patterns are applied in every room with redaction enabled, in addition
+	// to the room's own custom patterns.
+	Patterns []model.RedactionPattern
+}
+
+// RedactionService manages per-room transcript redaction policies.
+type RedactionService struct {
+	policyRepo      repository.RedactionPolicyRepository
+	roomRepo        repository.RoomRepository
+	participantRepo repository.ParticipantRepository
+	auditService    *AuditService
+	defaults        RedactionDefaults
+}
+
+func NewRedactionService(
+	policyRepo repository.RedactionPolicyRepository,
+	roomRepo repository.RoomRepository,
+	participantRepo repository.ParticipantRepository,
+	auditService *AuditService,
+	defaults RedactionDefaults,
+) (*RedactionService, error) {
+	// Fail at startup rather than on the first transcript request.
+	if _, err := NewRedactor(defaults.Categories, defaults.Patterns); err != nil {
+		return nil, err
+	}
+	return &RedactionService{
+		policyRepo:      policyRepo,
+		roomRepo:        roomRepo,
+		participantRepo: participantRepo,
+		auditService:    auditService,
+		defaults:        defaults,
+	}, nil
+}
+
+// GetPolicy returns the room's redaction policy to one of its members.
+func (s *RedactionService) GetPolicy(ctx context.Context, roomID, userID uuid.UUID) (*model.TranscriptRedactionPolicy, error) {
+	isMember, err := s.participantRepo.UserHasAccess(ctx, roomID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, ErrNotRoomMember
+	}
+	return s.policyFor(ctx, roomID)
+}
+
+// policyFor returns the room's redaction policy, or the default policy when
+// the room has not set one.
+func (s *RedactionService) policyFor(ctx context.Context, roomID uuid.UUID) (*model.TranscriptRedactionPolicy, error) {
+	policy, err := s.policyRepo.GetByRoomID(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		policy = &model.TranscriptRedactionPolicy{
+			RoomID:         roomID,
+			Enabled:        s.defaults.Enabled,
+			Categories:     append([]string{}, s.defaults.Categories...),
+			CustomPatterns: model.RedactionPatterns{},
+		}
+	}
+	return policy, nil
+}
+
+// UpdatePolicy replaces the room's redaction policy. Only the room owner may
+// change it, and every change is audit-logged.
+func (s *RedactionService) UpdatePolicy(ctx context.Context, roomID uuid.UUID, actor AuditActor, req *model.UpdateRedactionPolicyRequest) (*model.TranscriptRedactionPolicy, error) {
+	if err := s.requireRoomOwner(ctx, roomID, actor.UserID); err != nil {
+		return nil, err
+	}
+	if _, err := NewRedactor(req.Categories, req.CustomPatterns); err != nil {
+		return nil, err
+	}
+
+	categories := req.Categories
+	if categories == nil {
+		categories = []string{}
+	}
+	policy := &model.TranscriptRedactionPolicy{
+		RoomID:         roomID,
+		Enabled:        req.Enabled,
+		Categories:     categories,
+		CustomPatterns: req.CustomPatterns,
+		UpdatedBy:      &actor.UserID,
+	}
+	if err := s.policyRepo.Upsert(ctx, policy); err != nil {
+		return nil, err
+	}
+
+	details := model.Metadata{
+		"enabled":         policy.Enabled,
+		"categories":      policy.Categories,
+		"custom_patterns": len(policy.CustomPatterns),
+	}
+	if err := s.auditService.Record(ctx, roomID, actor, model.AuditActionRedactionPolicyUpdated, "room", roomID.String(), details); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// RedactorForRoom returns the redactor for the room's policy, or nil when
+// redaction is disabled in the room.
+func (s *RedactionService) RedactorForRoom(ctx context.Context, roomID uuid.UUID) (*Redactor, error) {
+	policy, err := s.policyFor(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	if !policy.Enabled {
+		return nil, nil
+	}
+
+	patterns := append(append([]model.RedactionPattern{}, s.defaults.Patterns...), policy.CustomPatterns...)
+	return NewRedactor(policy.Categories, patterns)
+}
+
+// CanAccessOriginal reports whether the user may read the unredacted
+// transcripts of the room, which only its owner may.
+func (s *RedactionService) CanAccessOriginal(ctx context.Context, roomID, userID uuid.UUID) error {
+	return s.requireRoomOwner(ctx, roomID, userID)
+}
+
+func (s *RedactionService) requireRoomOwner(ctx context.Context, roomID, userID uuid.UUID) error {
+	room, err := s.roomRepo.GetByID(ctx, roomID)
+	if err != nil {
+		return err
+	}
+	if room == nil {
+		return ErrRoomNotFound
+	}
+	if room.OwnerID != userID {
+		return ErrPermissionDenied
+	}
+	return nil
+}
@@ -0,0 +1,476 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"livekit-consulting/backend/internal/model"
+	"livekit-consulting/backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// maxTranscriptSegmentsPage caps how many segments one request may load.
+const maxTranscriptSegmentsPage = 500
+
+// maxTranscriptSearchHits caps how many hits one search may return.
+const maxTranscriptSearchHits = 50
+
+// transcriptSearchOverfetch is how many candidates a search loads per hit it
+// returns, to make up for candidates that no longer match once redacted.
+const transcriptSearchOverfetch = 3
+
+// ErrTranscriptKeyMismatch is returned when a requested storage key is not
+// one of the transcript message's files.
+var ErrTranscriptKeyMismatch = errors.New("invalid S3 key path for the given message")
+
+// TranscriptFileContent is a transcript file as served to a client.
+type TranscriptFileContent struct {
+	Body        []byte
+	ContentType string
+	Redacted    bool
+	Version     int
+}
+
+type TranscriptService struct {
+	transcriptRepo    repository.TranscriptRepository
+	messageRepo       repository.MessageRepository
+	participantRepo   repository.ParticipantRepository
+	roomRepo          repository.RoomRepository
+	transcriptStorage TranscriptStorage
+	redactionService  *RedactionService
+	auditService      *AuditService
+}
+
+func NewTranscriptService(
+	transcriptRepo repository.TranscriptRepository,
+	messageRepo repository.MessageRepository,
+	participantRepo repository.ParticipantRepository,
+	roomRepo repository.RoomRepository,
+	transcriptStorage TranscriptStorage,
+	redactionService *RedactionService,
+	auditService *AuditService,
+) *TranscriptService {
+	return &TranscriptService{
+		transcriptRepo:    transcriptRepo,
+		messageRepo:       messageRepo,
+		participantRepo:   participantRepo,
+		roomRepo:          roomRepo,
+		transcriptStorage: transcriptStorage,
+		redactionService:  redactionService,
+		auditService:      auditService,
+	}
+}
+
+// GetTranscriptFile loads one of the files of a transcript message at the
+// given version, or with all corrections applied when version is nil, and
+// redacts it according to the room's policy. With original set, the room
+// owner gets the file unredacted instead; that access is audit-logged before
+// anything is served.
+func (s *TranscriptService) GetTranscriptFile(ctx context.Context, roomID, messageID uuid.UUID, key string, version *int, actor AuditActor, original bool) (*TranscriptFileContent, error) {
+	isMember, err := s.participantRepo.UserHasAccess(ctx, roomID, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, ErrNotRoomMember
+	}
+
+	message, err := s.getTranscriptMessage(ctx, roomID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	keys := message.ExtraData.Transcript.S3Keys
+	if key == "" || (key != keys.JSON && key != keys.Text) {
+		return nil, ErrTranscriptKeyMismatch
+	}
+
+	currentVersion, err := s.transcriptRepo.GetCurrentVersion(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	targetVersion := currentVersion
+	if version != nil {
+		if *version < 0 || *version > currentVersion {
+			return nil, ErrTranscriptVersionNotFound
+		}
+		targetVersion = *version
+	}
+
+	var redactor *Redactor
+	if original {
+		if err := s.redactionService.CanAccessOriginal(ctx, roomID, actor.UserID); err != nil {
+			return nil, err
+		}
+		details := model.Metadata{"message_id": messageID.String(), "key": key, "version": targetVersion}
+		if err := s.auditService.Record(ctx, roomID, actor, model.AuditActionTranscriptOriginalAccessed, "message", messageID.String(), details); err != nil {
+			return nil, err
+		}
+	} else if redactor, err = s.redactionService.RedactorForRoom(ctx, roomID); err != nil {
+		return nil, err
+	}
+
+	fileReader, err := s.transcriptStorage.GetTranscriptFile(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer fileReader.Close()
+
+	body, err := io.ReadAll(fileReader)
+	if err != nil {
+		return nil, err
+	}
+
+	isText := key == keys.Text && key != keys.JSON
+	if targetVersion > 0 {
+		segments, err := s.transcriptRepo.GetSegmentsAtVersion(ctx, messageID, targetVersion)
+		if err != nil {
+			return nil, err
+		}
+		if body, err = applyTranscriptCorrections(body, segments, isText); err != nil {
+			return nil, err
+		}
+	}
+
+	content := &TranscriptFileContent{
+		Body:        body,
+		ContentType: "application/json",
+		Redacted:    redactor != nil,
+		Version:     targetVersion,
+	}
+	if isText {
+		content.ContentType = "text/plain; charset=utf-8"
+		content.Body = []byte(redactor.Redact(string(body)))
+	} else if content.Body, err = redactor.RedactTranscriptFile(body); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// IngestTranscript loads the JSON transcript of a meeting_transcript message
+// from storage and stores it as segments, one per utterance.
+func (s *TranscriptService) IngestTranscript(ctx context.Context, message *model.Message) error {
+	if message.ExtraData == nil || message.ExtraData.Transcript == nil || message.ExtraData.Transcript.S3Keys.JSON == "" {
+		return errors.New("message has no JSON transcript")
+	}
+	transcript := message.ExtraData.Transcript
+
+	fileReader, err := s.transcriptStorage.GetTranscriptFile(ctx, transcript.S3Keys.JSON)
+	if err != nil {
+		return err
+	}
+	defer fileReader.Close()
+
+	var file model.TranscriptFile
+	if err := json.NewDecoder(fileReader).Decode(&file); err != nil {
+		return err
+	}
+
+	sessionStart := file.SessionStart.Time
+	if sessionStart.IsZero() {
+		sessionStart = transcript.SessionStart
+	}
+	sessionEnd := file.SessionEnd.Time
+	if sessionEnd.IsZero() {
+		sessionEnd = transcript.SessionEnd
+	}
+
+	segments := buildTranscriptSegments(message.RoomID, message.ID, file.Items, sessionStart, sessionEnd)
+	return s.transcriptRepo.InsertSegments(ctx, segments)
+}
+
+// GetSegments returns a page of a transcript's segments, in order, starting
+// after the segment with index afterIndex (-1 for the first page).
+func (s *TranscriptService) GetSegments(ctx context.Context, roomID, messageID, userID uuid.UUID, afterIndex int, limit int) ([]*model.TranscriptSegment, error) {
+	isMember, err := s.participantRepo.UserHasAccess(ctx, roomID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, ErrNotRoomMember
+	}
+
+	if _, err := s.getTranscriptMessage(ctx, roomID, messageID); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 || limit > maxTranscriptSegmentsPage {
+		limit = maxTranscriptSegmentsPage
+	}
+
+	segments, err := s.transcriptRepo.GetSegments(ctx, messageID, afterIndex, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	redactor, err := s.redactionService.RedactorForRoom(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	return redactor.RedactSegments(segments), nil
+}
+
+// ExportTranscript renders a transcript message in the given format.
+// Transcripts uploaded before segments were stored are ingested first.
+func (s *TranscriptService) ExportTranscript(ctx context.Context, roomID, messageID, userID uuid.UUID, format TranscriptFormat) (*TranscriptExport, error) {
+	isMember, err := s.participantRepo.UserHasAccess(ctx, roomID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, ErrNotRoomMember
+	}
+
+	message, err := s.getTranscriptMessage(ctx, roomID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	room, err := s.roomRepo.GetByID(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	segments, err := s.getAllSegments(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) == 0 {
+		if err := s.IngestTranscript(ctx, message); err != nil {
+			return nil, err
+		}
+		if segments, err = s.getAllSegments(ctx, messageID); err != nil {
+			return nil, err
+		}
+	}
+
+	redactor, err := s.redactionService.RedactorForRoom(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionStart := message.ExtraData.Transcript.SessionStart
+	if sessionStart.IsZero() {
+		sessionStart = message.CreatedAt
+	}
+
+	return renderTranscript(&transcriptDocument{
+		RoomName:     room.RoomName,
+		SessionStart: sessionStart,
+		Segments:     redactor.RedactSegments(segments),
+	}, format)
+}
+
+// DigestTranscript builds the extractive summary of a transcript message
+// from its stored segments, as the ExtraData of the summary message.
+func (s *TranscriptService) DigestTranscript(ctx context.Context, message *model.Message) (*TranscriptDigest, *model.ExtraData, error) {
+	segments, err := s.getAllSegments(ctx, message.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(segments) == 0 {
+		return nil, nil, errors.New("transcript has no segments")
+	}
+
+	// The digest is posted in the room, so it only ever sees redacted text.
+	redactor, err := s.redactionService.RedactorForRoom(ctx, message.RoomID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	digest := SummarizeTranscript(redactor.RedactSegments(segments))
+	transcript := message.ExtraData.Transcript
+	extraData := &model.ExtraData{
+		Summary: &model.MeetingSummaryData{
+			Summary:             digest.Summary,
+			KeyPoints:           digest.KeyPoints,
+			SessionStart:        transcript.SessionStart,
+			SessionEnd:          transcript.SessionEnd,
+			Source:              model.SummarySourceExtractive,
+			TranscriptMessageID: &message.ID,
+			TalkTime:            digest.TalkTime,
+		},
+	}
+	if len(digest.ActionItems) > 0 {
+		extraData.ActionItems = &model.ActionItemsData{
+			Items:        digest.ActionItems,
+			SessionStart: transcript.SessionStart,
+		}
+	}
+	return digest, extraData, nil
+}
+
+func (s *TranscriptService) getAllSegments(ctx context.Context, messageID uuid.UUID) ([]*model.TranscriptSegment, error) {
+	var segments []*model.TranscriptSegment
+	after := -1
+	for {
+		page, err := s.transcriptRepo.GetSegments(ctx, messageID, after, maxTranscriptSegmentsPage)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, page...)
+		if len(page) < maxTranscriptSegmentsPage {
+			return segments, nil
+		}
+		after = page[len(page)-1].SegmentIndex
+	}
+}
+
+// SearchTranscripts searches the transcripts of one room.
+func (s *TranscriptService) SearchTranscripts(ctx context.Context, roomID, userID uuid.UUID, query string, limit int) ([]*model.TranscriptSearchHit, error) {
+	isMember, err := s.participantRepo.UserHasAccess(ctx, roomID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, ErrNotRoomMember
+	}
+
+	if limit <= 0 || limit > maxTranscriptSearchHits {
+		limit = maxTranscriptSearchHits
+	}
+	hits, err := s.transcriptRepo.Search(ctx, roomID, query, limit*transcriptSearchOverfetch)
+	if err != nil {
+		return nil, err
+	}
+	return s.redactSearchHits(ctx, hits, query, limit)
+}
+
+// SearchMyTranscripts searches the transcripts of every room the user
+// belongs to.
+func (s *TranscriptService) SearchMyTranscripts(ctx context.Context, userID uuid.UUID, query string, limit int) ([]*model.TranscriptSearchHit, error) {
+	if limit <= 0 || limit > maxTranscriptSearchHits {
+		limit = maxTranscriptSearchHits
+	}
+	hits, err := s.transcriptRepo.SearchForUser(ctx, userID, query, limit*transcriptSearchOverfetch)
+	if err != nil {
+		return nil, err
+	}
+	return s.redactSearchHits(ctx, hits, query, limit)
+}
+
+// redactSearchHits applies each hit's room policy to its text, then keeps up
+// to limit of the hits whose redacted text still matches the query and
+// highlights them. Hits that only matched on redacted values are dropped, so
+// a search cannot tell whether a masked value was said.
+func (s *TranscriptService) redactSearchHits(ctx context.Context, hits []*model.TranscriptSearchHit, query string, limit int) ([]*model.TranscriptSearchHit, error) {
+	if len(hits) == 0 {
+		return hits, nil
+	}
+
+	redactors := make(map[uuid.UUID]*Redactor)
+	texts := make([]string, len(hits))
+	for i, hit := range hits {
+		redactor, ok := redactors[hit.RoomID]
+		if !ok {
+			var err error
+			if redactor, err = s.redactionService.RedactorForRoom(ctx, hit.RoomID); err != nil {
+				return nil, err
+			}
+			redactors[hit.RoomID] = redactor
+		}
+		hit.Text = redactor.Redact(hit.Text)
+		texts[i] = hit.Text
+	}
+
+	snippets, err := s.transcriptRepo.Highlight(ctx, texts, query)
+	if err != nil {
+		return nil, err
+	}
+
+	matching := make([]*model.TranscriptSearchHit, 0, len(snippets))
+	for i, hit := range hits {
+		snippet, ok := snippets[i]
+		if !ok {
+			continue
+		}
+		hit.Snippet = snippet
+		matching = append(matching, hit)
+		if len(matching) == limit {
+			break
+		}
+	}
+	return matching, nil
+}
+
+// getTranscriptMessage loads a meeting_transcript message of the room.
+func (s *TranscriptService) getTranscriptMessage(ctx context.Context, roomID, messageID uuid.UUID) (*model.Message, error) {
+	message, err := s.messageRepo.GetByID(ctx, messageID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMessageNotFound
+		}
+		return nil, err
+	}
+	if message.RoomID != roomID || message.MessageType != model.MessageTypeMeetingTranscript || message.ExtraData == nil || message.ExtraData.Transcript == nil {
+		return nil, ErrMessageNotFound
+	}
+	return message, nil
+}
+
+// buildTranscriptSegments turns transcript items into segments. An utterance
+// lasts until the next one starts; the last one lasts until the session ends.
+// Items without text are skipped.
+func buildTranscriptSegments(roomID, messageID uuid.UUID, items []model.TranscriptItem, sessionStart, sessionEnd time.Time) []*model.TranscriptSegment {
+	if sessionStart.IsZero() {
+		for _, item := range items {
+			if !item.Timestamp.IsZero() {
+				sessionStart = item.Timestamp.Time
+				break
+			}
+		}
+	}
+	offset := func(t time.Time) int64 {
+		if t.IsZero() || t.Before(sessionStart) {
+			return 0
+		}
+		return t.Sub(sessionStart).Milliseconds()
+	}
+
+	segments := make([]*model.TranscriptSegment, 0, len(items))
+	for _, item := range items {
+		var parts []string
+		for _, content := range item.Content {
+			if text := strings.TrimSpace(content.Text); text != "" {
+				parts = append(parts, text)
+			}
+		}
+		if len(parts) == 0 {
+			continue
+		}
+
+		startMs := offset(item.Timestamp.Time)
+		if item.Timestamp.IsZero() && len(segments) > 0 {
+			startMs = segments[len(segments)-1].EndMs
+		}
+		if len(segments) > 0 {
+			// The previous utterance ends where this one starts.
+			previous := segments[len(segments)-1]
+			previous.EndMs = max(startMs, previous.StartMs)
+		}
+
+		segments = append(segments, &model.TranscriptSegment{
+			RoomID:          roomID,
+			MessageID:       messageID,
+			SegmentIndex:    len(segments),
+			SpeakerIdentity: item.SpeakerIdentity,
+			SpeakerName:     item.SpeakerName,
+			Role:            item.Role,
+			StartMs:         startMs,
+			EndMs:           startMs,
+			Text:            strings.Join(parts, " "),
+		})
+	}
+
+	if len(segments) > 0 {
+		last := segments[len(segments)-1]
+		last.EndMs = max(offset(sessionEnd), last.StartMs)
+	}
+
+	return segments
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"livekit-consulting/backend/internal/config"
+)
+
+// ErrTranscriptFileNotFound is returned when a transcript file is missing
+// from storage.
+var ErrTranscriptFileNotFound = errors.New("transcript file not found")
+
+// TranscriptStorage reads the transcript files the meeting agent uploads.
+type TranscriptStorage interface {
+	GetTranscriptFile(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// NewTranscriptStorage creates the transcript storage selected by
+// TRANSCRIPT_STORAGE_PROVIDER. The minio and gcs backends read through the
+// client of the attachment file storage, which must use the same provider.
+func NewTranscriptStorage(cfg *config.Config, fileStorage FileStorage) (TranscriptStorage, error) {
+	switch cfg.TranscriptStorageProvider {
+	case "s3":
+		return NewS3TranscriptStorage(cfg)
+	case "minio":
+		return NewMinioTranscriptStorage(cfg, fileStorage)
+	case "gcs":
+		return NewGCSTranscriptStorage(cfg, fileStorage)
+	case "local":
+		return NewLocalTranscriptStorage(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported transcript storage provider %q", cfg.TranscriptStorageProvider)
+	}
+}
+
+// transcriptBucket is the bucket transcripts are read from, which defaults
+// to the attachments bucket.
+func transcriptBucket(cfg *config.Config, fileStorageBucket string) string {
+	if cfg.TranscriptStorageBucket != "" {
+		return cfg.TranscriptStorageBucket
+	}
+	return fileStorageBucket
+}
@@ -0,0 +1,286 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"livekit-consulting/backend/internal/model"
+)
+
+const (
+	summarySentenceCount = 3
+	keyPointCount        = 5
+	// minSummarySentenceWords keeps filler such as "Sounds good." out of the
+	// summary.
+	minSummarySentenceWords = 5
+	maxActionItems          = 20
+)
+
+// TranscriptDigest is the extractive summary of a transcript.
+type TranscriptDigest struct {
+	Summary     string
+	KeyPoints   []string
+	ActionItems []model.ActionItem
+	TalkTime    []model.SpeakerTalkTime
+}
+
+// transcriptSentence is one sentence of a transcript segment.
+type transcriptSentence struct {
+	Text     string
+	Speaker  string
+	StartMs  int64
+	Position int
+	Terms    []string
+	Score    float64
+}
+
+var (
+	sentenceBoundary = regexp.MustCompile(`[.!?]+(\s+|$)`)
+	wordPattern      = regexp.MustCompile(`[\p{L}\p{N}']+`)
+
+	// Phrases that commit someone to doing something. The first group says
+	// who: a first-person commitment belongs to the speaker.
+	commitmentPattern = regexp.MustCompile(`(?i)\b(i|we)\s*(?:'ll|will|shall|am going to|'m going to|are going to|'re going to|need to|have to|should|can take|'ll take)\b`)
+	actionPhrase      = regexp.MustCompile(`(?i)\b(action items?|to-?do|follow[- ]?up|next steps?|take care of|assign(?:ed)? to)\b`)
+	requestPattern    = regexp.MustCompile(`(?i)\b(can|could|would) you (?:please )?\b`)
+	dueDatePattern    = regexp.MustCompile(`(?i)\b(?:by|before|until|due)\s+((?:next |this )?(?:monday|tuesday|wednesday|thursday|friday|saturday|sunday|week|month)|tomorrow|today|tonight|eod|eow|end of (?:the )?(?:day|week|month|quarter)|(?:jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]* \d{1,2}(?:st|nd|rd|th)?|\d{1,2}/\d{1,2})\b`)
+)
+
+// summaryStopWords are left out when scoring sentences.
+var summaryStopWords = map[string]bool{}
+
+func init() {
+	for _, word := range strings.Fields(`a about above after again all also am an and any are aren't as at
+		be because been before being below between both but by can can't could couldn't
+		did didn't do does doesn't doing don't down during each few for from further
+		get got gonna had hadn't has hasn't have haven't having he he's her here here's hers
+		him his how i i'd i'll i'm i've if in into is isn't it it's its itself just know
+		let's like me more most my no nor not now of off okay ok on once only or other our
+		ours out over own really right same she should shouldn't so some such than that
+		that's the their them then there there's these they they're this those through
+		to too um uh under until up very was wasn't we we'll we're we've were weren't what
+		when where which while who why will with won't would wouldn't yeah yes you you'll
+		you're your yours`) {
+		summaryStopWords[word] = true
+	}
+}
+
+// SummarizeTranscript builds a deterministic extractive digest of a
+// transcript: the most salient sentences, candidate action items and talk
+// time per speaker. The same segments always produce the same digest.
+func SummarizeTranscript(segments []*model.TranscriptSegment) *TranscriptDigest {
+	sentences := splitTranscriptSentences(segments)
+	scoreTranscriptSentences(sentences)
+
+	ranked := make([]*transcriptSentence, 0, len(sentences))
+	for _, sentence := range sentences {
+		if len(sentence.Terms) > 0 && len(wordPattern.FindAllString(sentence.Text, -1)) >= minSummarySentenceWords {
+			ranked = append(ranked, sentence)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].Position < ranked[j].Position
+	})
+
+	summaryCount := min(summarySentenceCount, len(ranked))
+	summary := inTranscriptOrder(ranked[:summaryCount])
+	keyPoints := inTranscriptOrder(ranked[summaryCount:min(summaryCount+keyPointCount, len(ranked))])
+
+	digest := &TranscriptDigest{
+		ActionItems: detectActionItems(sentences),
+		TalkTime:    computeTalkTime(segments),
+	}
+	var summaryText []string
+	for _, sentence := range summary {
+		summaryText = append(summaryText, sentence.Text)
+	}
+	digest.Summary = strings.Join(summaryText, " ")
+	for _, sentence := range keyPoints {
+		digest.KeyPoints = append(digest.KeyPoints, sentence.Text)
+	}
+
+	return digest
+}
+
+func splitTranscriptSentences(segments []*model.TranscriptSegment) []*transcriptSentence {
+	var sentences []*transcriptSentence
+	for _, segment := range segments {
+		text := strings.TrimSpace(segment.Text)
+		start := 0
+		for _, loc := range sentenceBoundary.FindAllStringIndex(text, -1) {
+			sentences = appendTranscriptSentence(sentences, segment, text[start:loc[1]])
+			start = loc[1]
+		}
+		sentences = appendTranscriptSentence(sentences, segment, text[start:])
+	}
+	return sentences
+}
+
+func appendTranscriptSentence(sentences []*transcriptSentence, segment *model.TranscriptSegment, text string) []*transcriptSentence {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return sentences
+	}
+
+	var terms []string
+	for _, word := range wordPattern.FindAllString(strings.ToLower(text), -1) {
+		if len(word) > 2 && !summaryStopWords[word] {
+			terms = append(terms, word)
+		}
+	}
+
+	return append(sentences, &transcriptSentence{
+		Text:     text,
+		Speaker:  speakerLabel(segment),
+		StartMs:  segment.StartMs,
+		Position: len(sentences),
+		Terms:    terms,
+	})
+}
+
+// scoreTranscriptSentences scores each sentence by the average frequency of
+// its terms across the whole transcript, so sentences about what the meeting
+// kept coming back to rank highest.
+func scoreTranscriptSentences(sentences []*transcriptSentence) {
+	frequency := make(map[string]int)
+	for _, sentence := range sentences {
+		for _, term := range sentence.Terms {
+			frequency[term]++
+		}
+	}
+
+	for _, sentence := range sentences {
+		if len(sentence.Terms) == 0 {
+			continue
+		}
+		total := 0
+		for _, term := range sentence.Terms {
+			total += frequency[term]
+		}
+		sentence.Score = float64(total) / float64(len(sentence.Terms))
+	}
+}
+
+func inTranscriptOrder(sentences []*transcriptSentence) []*transcriptSentence {
+	ordered := append([]*transcriptSentence(nil), sentences...)
+	sort.Slice(ordered, func(i, j int) bool {
+		return ordered[i].Position < ordered[j].Position
+	})
+	return ordered
+}
+
+// detectActionItems picks sentences that read like commitments, requests or
+// explicit action items, with the deadline they mention if any.
+func detectActionItems(sentences []*transcriptSentence) []model.ActionItem {
+	var items []model.ActionItem
+	seen := make(map[string]bool)
+	for _, sentence := range sentences {
+		if len(items) == maxActionItems {
+			break
+		}
+
+		commitment := commitmentPattern.FindStringSubmatch(sentence.Text)
+		due := dueDatePattern.FindStringSubmatch(sentence.Text)
+		if commitment == nil && due == nil && !actionPhrase.MatchString(sentence.Text) && !requestPattern.MatchString(sentence.Text) {
+			continue
+		}
+		// "We will" is how people describe plans in general; only take it
+		// when it comes with a deadline or explicit action wording.
+		if commitment != nil && strings.EqualFold(commitment[1], "we") && due == nil && !actionPhrase.MatchString(sentence.Text) {
+			continue
+		}
+
+		key := strings.ToLower(sentence.Text)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		startMs := sentence.StartMs
+		item := model.ActionItem{
+			Text:    sentence.Text,
+			StartMs: &startMs,
+		}
+		if commitment != nil && strings.EqualFold(commitment[1], "i") {
+			item.Assignee = sentence.Speaker
+		}
+		if due != nil {
+			item.DueDate = strings.ToLower(due[1])
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// computeTalkTime adds up how long each speaker talked, most talkative first.
+func computeTalkTime(segments []*model.TranscriptSegment) []model.SpeakerTalkTime {
+	var talkTime []model.SpeakerTalkTime
+	index := make(map[string]int)
+	var total int64
+	for _, segment := range segments {
+		key := segment.SpeakerIdentity
+		if key == "" {
+			key = speakerLabel(segment)
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(talkTime)
+			index[key] = i
+			talkTime = append(talkTime, model.SpeakerTalkTime{
+				SpeakerIdentity: segment.SpeakerIdentity,
+				SpeakerName:     speakerLabel(segment),
+			})
+		}
+
+		duration := max(segment.EndMs-segment.StartMs, 0)
+		talkTime[i].TalkTimeMs += duration
+		talkTime[i].Segments++
+		talkTime[i].Words += len(wordPattern.FindAllString(segment.Text, -1))
+		total += duration
+	}
+
+	for i := range talkTime {
+		if total > 0 {
+			talkTime[i].Share = float64(talkTime[i].TalkTimeMs) / float64(total)
+		}
+	}
+	sort.SliceStable(talkTime, func(i, j int) bool {
+		if talkTime[i].TalkTimeMs != talkTime[j].TalkTimeMs {
+			return talkTime[i].TalkTimeMs > talkTime[j].TalkTimeMs
+		}
+		return talkTime[i].SpeakerName < talkTime[j].SpeakerName
+	})
+	return talkTime
+}
+
+// formatTranscriptDigest renders a digest as the content of its message.
+func formatTranscriptDigest(digest *TranscriptDigest) string {
+	var content strings.Builder
+	content.WriteString("Meeting summary:")
+	if digest.Summary != "" {
+		content.WriteString("\n" + digest.Summary)
+	}
+	if len(digest.ActionItems) > 0 {
+		content.WriteString("\n\nAction items:")
+		for _, item := range digest.ActionItems {
+			content.WriteString("\n- " + item.Text)
+			if item.Assignee != "" {
+				content.WriteString(" (" + item.Assignee + ")")
+			}
+		}
+	}
+	if len(digest.TalkTime) > 0 {
+		content.WriteString("\n\nTalk time:")
+		for _, speaker := range digest.TalkTime {
+			duration := time.Duration(speaker.TalkTimeMs) * time.Millisecond
+			content.WriteString(fmt.Sprintf("\n- %s: %s (%.0f%%)", speaker.SpeakerName, duration.Round(time.Second), speaker.Share*100))
+		}
+	}
+	return content.String()
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+    "errors"
     "time"
 
     "github.com/dgrijalva/jwt-go"
@@ -19,3 +20,18 @@ func GenerateJWT(userID, email, secret string) (string, time.Time, error) {
 
     return tokenString, expirationTime, err
 }
+
+func ParseJWT(tokenString, secret string) (*jwt.StandardClaims, error) {
+    claims := &jwt.StandardClaims{}
+    token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+        return []byte(secret), nil
+    })
+    if err != nil {
+        return nil, err
+    }
+    if !token.Valid {
+        return nil, errors.New("invalid token")
+    }
+
+    return claims, nil
+}
@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MentionAll is the token that mentions every participant of a room.
+const MentionAll = "all"
+
+// mentionPattern matches @name tokens that start a word, so email addresses
+// such as bob@example.com are not treated as mentions.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@(\w[\w.-]*)`)
+
+// ParseMentions returns the distinct lower-cased names mentioned in content,
+// in order of first appearance.
+func ParseMentions(content string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		// Trailing punctuation belongs to the sentence, not the name.
+		name := strings.ToLower(strings.TrimRight(match[1], ".-"))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}